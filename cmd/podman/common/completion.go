@@ -16,6 +16,7 @@ import (
 	"github.com/containers/common/pkg/config"
 	"github.com/containers/common/pkg/ssh"
 	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/transports"
 	"github.com/containers/podman/v5/cmd/podman/registry"
 	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/libpod/events"
@@ -1425,7 +1426,7 @@ func AutocompleteEventFilter(cmd *cobra.Command, args []string, toComplete strin
 			events.Exited.String(), events.Export.String(), events.Import.String(), events.Init.String(), events.Kill.String(),
 			events.LoadFromArchive.String(), events.Mount.String(), events.NetworkConnect.String(),
 			events.NetworkDisconnect.String(), events.Pause.String(), events.Prune.String(), events.Pull.String(),
-			events.PullError.String(), events.Push.String(), events.Refresh.String(), events.Remove.String(),
+			events.PullError.String(), events.PullRecord.String(), events.Push.String(), events.Refresh.String(), events.Remove.String(),
 			events.Rename.String(), events.Renumber.String(), events.Restart.String(), events.Restore.String(),
 			events.Save.String(), events.Start.String(), events.Stop.String(), events.Sync.String(), events.Tag.String(),
 			events.Unmount.String(), events.Unpause.String(), events.Untag.String(), events.Update.String(),
@@ -1481,6 +1482,37 @@ func AutocompleteManifestFormat(cmd *cobra.Command, args []string, toComplete st
 	return types, cobra.ShellCompDirectiveNoFileComp
 }
 
+// AutocompleteImageTransport - Autocomplete image transports.
+func AutocompleteImageTransport(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return transports.ListNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// AutocompletePullProgress - Autocomplete pull progress modes.
+// -> "auto", "json", "none", "plain"
+func AutocompletePullProgress(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	modes := []string{"auto", "json", "none", "plain"}
+	if registry.IsRemote() {
+		// JSON progress is printed from the progress events, which
+		// are not available over the API.
+		modes = []string{"auto", "none", "plain"}
+	}
+	return modes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// AutocompletePullReferrerTypes - Autocomplete pull referrer types.
+// -> "attestation", "sbom", "signature"
+func AutocompletePullReferrerTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	types := []string{"attestation", "sbom", "signature"}
+	return types, cobra.ShellCompDirectiveNoFileComp
+}
+
+// AutocompletePullDigestAlgorithm - Autocomplete pull digest algorithms.
+// -> "sha256", "sha512"
+func AutocompletePullDigestAlgorithm(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	algorithms := []string{"sha256", "sha512"}
+	return algorithms, cobra.ShellCompDirectiveNoFileComp
+}
+
 // AutocompleteNetworkDriver - Autocomplete network driver option.
 func AutocompleteNetworkDriver(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	engine, err := setupContainerEngine(cmd)
@@ -1488,7 +1520,7 @@ func AutocompleteNetworkDriver(cmd *cobra.Command, args []string, toComplete str
 		cobra.CompErrorln(err.Error())
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	info, err := engine.Info(registry.Context())
+	info, err := engine.Info(registry.Context(), entities.SystemInfoOptions{})
 	if err != nil {
 		cobra.CompErrorln(err.Error())
 		return nil, cobra.ShellCompDirectiveNoFileComp
@@ -1,21 +1,42 @@
 package images
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/url"
 	"os"
+	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/containers/buildah/pkg/cli"
 	"github.com/containers/common/pkg/auth"
 	"github.com/containers/common/pkg/completion"
+	"github.com/containers/common/pkg/config"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/transports"
 	"github.com/containers/image/v5/types"
 	"github.com/containers/podman/v5/cmd/podman/common"
 	"github.com/containers/podman/v5/cmd/podman/registry"
 	"github.com/containers/podman/v5/cmd/podman/utils"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/util"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
+	"github.com/mattn/go-shellwords"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // pullOptionsWrapper wraps entities.ImagePullOptions and prevents leaking
@@ -24,7 +45,56 @@ type pullOptionsWrapper struct {
 	entities.ImagePullOptions
 	TLSVerifyCLI   bool // CLI only
 	CredentialsCLI string
+	EnvCredsCLI    string
 	DecryptionKeys []string
+	FailOnWarning  bool
+	// ConcurrentImages is the number of images pulled in parallel
+	ConcurrentImages uint
+	RepoDigestOnly   bool
+	Transport        string
+	ProgressMode     string
+	NoTruncErrors    bool
+	PolicyCLI        string
+	ExitCodeOnNoop   int
+	MinFreeSpaceCLI  string
+	ProxyCLI         string
+	NoProxyCLI       []string
+	AnnotateCLI      []string
+	SinceEventCLI    string
+	QuietOnCacheHit  bool
+	StopOnError      bool
+
+	// WaitForRegistryCLI is parsed into ImagePullOptions.WaitForRegistry
+	WaitForRegistryCLI string
+	// JSON prints an entities.ImagePullResult instead of the image IDs
+	JSON bool
+	// SummaryOnly prints the number of pulled images instead of their IDs
+	SummaryOnly bool
+	// AfterPullExec is a command to run for each pulled image
+	AfterPullExec string
+	// IgnoreHookErrors only warns if AfterPullExec fails
+	IgnoreHookErrors bool
+	// LockDir is a directory to lock a file per image in while pulling it
+	LockDir string
+	// VerifyLayersCLI is a file with the diff IDs parsed into
+	// ImagePullOptions.VerifyLayers
+	VerifyLayersCLI string
+	// JSONProgress prints the progress of each pull as JSON messages
+	JSONProgress bool
+}
+
+// plainWriter hides the underlying file from c/image, which only renders
+// progress bars with cursor movements when writing to a terminal.
+type plainWriter struct {
+	io.Writer
+}
+
+// pullResult is the outcome of pulling a single image.  done is closed once
+// the pull has finished.
+type pullResult struct {
+	report *entities.ImagePullReport
+	err    error
+	done   chan struct{}
 }
 
 var (
@@ -81,10 +151,19 @@ func pullFlags(cmd *cobra.Command) {
 
 	flags.BoolVarP(&pullOptions.AllTags, "all-tags", "a", false, "All tagged images in the repository will be pulled")
 
+	afterPullExecFlagName := "after-pull-exec"
+	flags.StringVar(&pullOptions.AfterPullExec, afterPullExecFlagName, "", "Run `COMMAND` for each pulled image, with the image reference as its last argument")
+	_ = cmd.RegisterFlagCompletionFunc(afterPullExecFlagName, completion.AutocompleteDefault)
+	flags.BoolVar(&pullOptions.IgnoreHookErrors, "ignore-hook-errors", false, "Only warn if the --after-pull-exec command fails")
+
 	credsFlagName := "creds"
 	flags.StringVar(&pullOptions.CredentialsCLI, credsFlagName, "", "`Credentials` (USERNAME:PASSWORD) to use for authenticating to a registry")
 	_ = cmd.RegisterFlagCompletionFunc(credsFlagName, completion.AutocompleteNone)
 
+	envCredsFlagName := "env-creds"
+	flags.StringVar(&pullOptions.EnvCredsCLI, envCredsFlagName, "", "Read `Credentials` (USERNAME:PASSWORD) to use for authenticating to a registry from the environment variable `VARNAME`")
+	_ = cmd.RegisterFlagCompletionFunc(envCredsFlagName, completion.AutocompleteNone)
+
 	archFlagName := "arch"
 	flags.StringVar(&pullOptions.Arch, archFlagName, "", "Use `ARCH` instead of the architecture of the machine for choosing images")
 	_ = cmd.RegisterFlagCompletionFunc(archFlagName, completion.AutocompleteArch)
@@ -102,9 +181,34 @@ func pullFlags(cmd *cobra.Command) {
 	_ = cmd.RegisterFlagCompletionFunc(platformFlagName, completion.AutocompleteNone)
 
 	flags.Bool("disable-content-trust", false, "This is a Docker specific option and is a NOOP")
-	flags.BoolVarP(&pullOptions.Quiet, "quiet", "q", false, "Suppress output information when pulling images")
+	flags.BoolVar(&pullOptions.JSON, "json", false, "Print the result of the pull as JSON")
+	flags.BoolVarP(&pullOptions.Quiet, "quiet", "q", false, "Suppress output information when pulling images (shorthand for --progress none)")
+
+	progressFlagName := "progress"
+	progressDescription := "How to report the pull progress: `none`, auto, plain or json"
+	if registry.IsRemote() {
+		progressDescription = "How to report the pull progress: `none`, auto or plain"
+	}
+	flags.StringVar(&pullOptions.ProgressMode, progressFlagName, "auto", progressDescription)
+	_ = cmd.RegisterFlagCompletionFunc(progressFlagName, common.AutocompletePullProgress)
+
+	lockDirFlagName := "lock-dir"
+	flags.StringVar(&pullOptions.LockDir, lockDirFlagName, "", "Lock a file per image in `DIRECTORY` while pulling it, so concurrent pulls of an image wait for each other")
+	_ = cmd.RegisterFlagCompletionFunc(lockDirFlagName, completion.AutocompleteDefault)
+
+	flags.BoolVar(&pullOptions.NoTruncErrors, "no-trunc-errors", false, "Prefix errors with the image and include all details reported by the registry")
+	policyFlagName := "policy"
+	flags.StringVar(&pullOptions.PolicyCLI, policyFlagName, "always", `Pull image policy ("always"|"missing"|"never"|"newer")`)
+	_ = cmd.RegisterFlagCompletionFunc(policyFlagName, common.AutocompletePullOption)
+	flags.BoolVar(&pullOptions.RepoDigestOnly, "repo-digest-only", false, "Print only the repo digest (NAME@DIGEST) of each pulled image")
+	flags.BoolVar(&pullOptions.StopOnError, "stop-on-error", false, "Do not pull the remaining images after the first failure")
+	flags.SetNormalizeFunc(utils.StopOnErrorAliasFlags)
 	flags.BoolVar(&pullOptions.TLSVerifyCLI, "tls-verify", true, "Require HTTPS and verify certificates when contacting registries")
 
+	transportFlagName := "transport"
+	flags.StringVar(&pullOptions.Transport, transportFlagName, "", "Interpret IMAGE as a reference in `TRANSPORT` instead of parsing its prefix")
+	_ = cmd.RegisterFlagCompletionFunc(transportFlagName, common.AutocompleteImageTransport)
+
 	authfileFlagName := "authfile"
 	flags.StringVar(&pullOptions.Authfile, authfileFlagName, auth.GetDefaultAuthFile(), "Path of the authentication file. Use REGISTRY_AUTH_FILE environment variable to override")
 	_ = cmd.RegisterFlagCompletionFunc(authfileFlagName, completion.AutocompleteDefault)
@@ -113,6 +217,10 @@ func pullFlags(cmd *cobra.Command) {
 	flags.StringArrayVar(&pullOptions.DecryptionKeys, decryptionKeysFlagName, nil, "Key needed to decrypt the image (e.g. /path/to/key.pem)")
 	_ = cmd.RegisterFlagCompletionFunc(decryptionKeysFlagName, completion.AutocompleteDefault)
 
+	concurrentImagesFlagName := "concurrent-images"
+	flags.UintVar(&pullOptions.ConcurrentImages, concurrentImagesFlagName, 1, "Number of images to pull in parallel")
+	_ = cmd.RegisterFlagCompletionFunc(concurrentImagesFlagName, completion.AutocompleteNone)
+
 	retryFlagName := "retry"
 	flags.Uint(retryFlagName, registry.RetryDefault(), "number of times to retry in case of failure when performing pull")
 	_ = cmd.RegisterFlagCompletionFunc(retryFlagName, completion.AutocompleteNone)
@@ -127,6 +235,109 @@ func pullFlags(cmd *cobra.Command) {
 		flags.StringVar(&pullOptions.CertDir, certDirFlagName, "", "`Pathname` of a directory containing TLS certificates and keys")
 		_ = cmd.RegisterFlagCompletionFunc(certDirFlagName, completion.AutocompleteDefault)
 
+		flags.BoolVar(&pullOptions.AcceptGzipOnly, "accept-gzip-only", false, "Only pull gzip-compressed layers, preferring the gzip instances of image indexes")
+
+		flags.BoolVar(&pullOptions.AcceptSchema1, "accept-schema1", false, "Allow pulling images that only provide a deprecated Docker schema 1 manifest")
+
+		annotateFlagName := "annotate"
+		flags.StringArrayVar(&pullOptions.AnnotateCLI, annotateFlagName, nil, "Record the local annotation `KEY=VALUE` with the pulled image")
+		_ = cmd.RegisterFlagCompletionFunc(annotateFlagName, completion.AutocompleteNone)
+
+		blobCacheFlagName := "blob-cache"
+		flags.StringVar(&pullOptions.BlobCache, blobCacheFlagName, "", "`Directory` of a blob cache, possibly shared with other Podman instances, to use before fetching blobs from the registry")
+		_ = cmd.RegisterFlagCompletionFunc(blobCacheFlagName, completion.AutocompleteDefault)
+
+		cacheDirFlagName := "cache-dir"
+		flags.StringVar(&pullOptions.TokenCacheDir, cacheDirFlagName, "", "`Directory` to cache registry tokens in between pulls")
+		_ = cmd.RegisterFlagCompletionFunc(cacheDirFlagName, completion.AutocompleteDefault)
+
+		flags.BoolVar(&pullOptions.CheckpointCompatible, "checkpoint-compatible", false, "Warn if containers of the pulled images cannot be checkpointed and restored with CRIU on this host")
+
+		digestAlgorithmFlagName := "digest-algorithm"
+		flags.StringVar(&pullOptions.DigestAlgorithm, digestAlgorithmFlagName, "", "Prefer `ALGORITHM` (sha256, sha512) for the digests of the pulled blobs")
+		_ = cmd.RegisterFlagCompletionFunc(digestAlgorithmFlagName, common.AutocompletePullDigestAlgorithm)
+
+		flags.BoolVar(&pullOptions.RepairLayers, "download-only-missing-layers", false, "Only pull images that are present if some of their layers are missing, and only download those layers")
+
+		exitCodeOnNoopFlagName := "exit-code-on-noop"
+		flags.IntVar(&pullOptions.ExitCodeOnNoop, exitCodeOnNoopFlagName, 0, "Exit with `CODE` if all images are already present and nothing is pulled")
+		_ = cmd.RegisterFlagCompletionFunc(exitCodeOnNoopFlagName, completion.AutocompleteNone)
+
+		maxLayerRetriesFlagName := "max-layer-retries"
+		flags.UintVar(&pullOptions.MaxLayerRetries, maxLayerRetriesFlagName, 0, "Number of times to retry fetching a single layer, in addition to --retry")
+		_ = cmd.RegisterFlagCompletionFunc(maxLayerRetriesFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.MirrorOnly, "mirror-only", false, "Only pull from the mirrors configured in registries.conf, never from the upstream registry")
+
+		minFreeSpaceFlagName := "min-free-space"
+		flags.StringVar(&pullOptions.MinFreeSpaceCLI, minFreeSpaceFlagName, "", "Abort the pull if less than `SIZE` (e.g. 1GB) would remain free on the graph root")
+		_ = cmd.RegisterFlagCompletionFunc(minFreeSpaceFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.NormalizeName, "normalize-name", false, "Print the fully-qualified reference the image name was resolved to")
+
+		noProxyFlagName := "no-proxy"
+		flags.StringSliceVar(&pullOptions.NoProxyCLI, noProxyFlagName, nil, "`HOSTS` to contact directly instead of through the proxy, overriding $NO_PROXY")
+		_ = cmd.RegisterFlagCompletionFunc(noProxyFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.FIPS, "fips", false, "Fail the pull if a registry connection or a digest does not use FIPS-approved algorithms")
+
+		flags.BoolVar(&pullOptions.FailOnWarning, "fail-on-warning", false, "Fail the pull if any warnings are logged while pulling")
+		flags.BoolVar(&pullOptions.ReportReplaced, "overwrite", false, "Report the ID of the image the pulled name referred to before if the pull moved it (names are always moved atomically)")
+		preferPlatformVariantFlagName := "prefer-platform-variant"
+		flags.StringSliceVar(&pullOptions.PreferPlatformVariants, preferPlatformVariantFlagName, nil, "Choose the first of the `VARIANTS` (e.g. v8,v7) available for the platform, falling back to any variant")
+		_ = cmd.RegisterFlagCompletionFunc(preferPlatformVariantFlagName, completion.AutocompleteNone)
+
+		prefetchReferrersFlagName := "prefetch-referrers"
+		flags.StringSliceVar(&pullOptions.PrefetchReferrers, prefetchReferrersFlagName, nil, "Also pull the referrers of the given `TYPES` (signature, attestation, sbom) of the image")
+		_ = cmd.RegisterFlagCompletionFunc(prefetchReferrersFlagName, common.AutocompletePullReferrerTypes)
+
+		proxyFlagName := "proxy"
+		flags.StringVar(&pullOptions.ProxyCLI, proxyFlagName, "", "`URL` of the HTTP proxy to use for this pull, overriding $HTTP_PROXY and $HTTPS_PROXY")
+		_ = cmd.RegisterFlagCompletionFunc(proxyFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.RecordToDB, "record-to-db", false, "Write a pull-record event with the digest, duration and result of the pull to the event log")
+		redirectAuthToFlagName := "redirect-auth-to"
+		flags.StringVar(&pullOptions.RedirectAuthTo, redirectAuthToFlagName, "", "Request bearer tokens from `HOST` instead of the host of the realm announced by the registry")
+		_ = cmd.RegisterFlagCompletionFunc(redirectAuthToFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.QuietOnCacheHit, "quiet-on-cache-hit", false, "Do not print anything for images that are already present and not pulled")
+		flags.BoolVar(&pullOptions.SummaryOnly, "summary-only", false, "Print a summary of how many images were pulled instead of the image IDs")
+		flags.BoolVar(&pullOptions.ManifestOnly, "print-manifest", false, "Print the raw manifests of the image instead of pulling it")
+
+		// Shadows the hidden global flag so that it shows up in the help of
+		// pull. Both are applied to the runtime the same way.
+		registriesConfFlagName := "registries-conf"
+		flags.StringVar(&registry.PodmanConfig().RegistriesConf, registriesConfFlagName, "", "`Path` to a registries.conf to use for this pull only")
+		_ = cmd.RegisterFlagCompletionFunc(registriesConfFlagName, completion.AutocompleteDefault)
+
+		reuseBlobsFromFlagName := "reuse-blobs-from"
+		flags.StringVar(&pullOptions.ReuseBlobsFrom, reuseBlobsFromFlagName, "", "Reuse the layers of the local `IMAGE` that have the same diff IDs instead of pulling them")
+		_ = cmd.RegisterFlagCompletionFunc(reuseBlobsFromFlagName, common.AutocompleteImages)
+
+		storeOptFlagName := "store-opt"
+		flags.StringArray(storeOptFlagName, nil, "Override a storage option for this pull only (e.g. driver=vfs or overlay.mountopt=nodev)")
+		_ = cmd.RegisterFlagCompletionFunc(storeOptFlagName, completion.AutocompleteNone)
+
+		flags.BoolVar(&pullOptions.StrictPlatform, "strict-platform", false, "Fail if the image does not provide exactly the requested platform")
+
+		sinceEventFlagName := "since-event"
+		flags.StringVar(&pullOptions.SinceEventCLI, sinceEventFlagName, "", "With --all-tags, only pull the tags whose image was created after `TIMESTAMP`")
+		_ = cmd.RegisterFlagCompletionFunc(sinceEventFlagName, completion.AutocompleteNone)
+
+		traceFlagName := "trace"
+		flags.StringVar(&pullOptions.TraceFile, traceFlagName, "", "Append a trace of the HTTP requests made to registries to `FILE`")
+		_ = cmd.RegisterFlagCompletionFunc(traceFlagName, completion.AutocompleteDefault)
+
+		flags.BoolVar(&pullOptions.ValidateTar, "validate-tar", false, "Verify the extracted layers against their diff IDs after pulling")
+
+		verifyLayersFlagName := "verify-layers"
+		flags.StringVar(&pullOptions.VerifyLayersCLI, verifyLayersFlagName, "", "Fail if the diff IDs of the pulled layers differ from the ones listed in `FILE`")
+		_ = cmd.RegisterFlagCompletionFunc(verifyLayersFlagName, completion.AutocompleteDefault)
+
+		waitForRegistryFlagName := "wait-for-registry"
+		flags.StringVar(&pullOptions.WaitForRegistryCLI, waitForRegistryFlagName, "", "Wait up to `DURATION` (e.g. 60s) for the registry to become reachable before pulling")
+		_ = cmd.RegisterFlagCompletionFunc(waitForRegistryFlagName, completion.AutocompleteNone)
+
 		signaturePolicyFlagName := "signature-policy"
 		flags.StringVar(&pullOptions.SignaturePolicy, signaturePolicyFlagName, "", "`Pathname` of signature policy file (not usually used)")
 		_ = flags.MarkHidden(signaturePolicyFlagName)
@@ -161,11 +372,54 @@ func imagePull(cmd *cobra.Command, args []string) error {
 		pullOptions.RetryDelay = val
 	}
 
+	if pullOptions.JSON && (pullOptions.RepoDigestOnly || pullOptions.ManifestOnly) {
+		return errors.New("--json option can not be specified with --repo-digest-only or --print-manifest")
+	}
+	if pullOptions.SummaryOnly && (pullOptions.JSON || pullOptions.RepoDigestOnly || pullOptions.ManifestOnly) {
+		return errors.New("--summary-only option can not be specified with --json, --repo-digest-only or --print-manifest")
+	}
+
+	var afterPullExec []string
+	if pullOptions.AfterPullExec != "" {
+		parsed, err := shellwords.Parse(pullOptions.AfterPullExec)
+		if err != nil {
+			return fmt.Errorf("parsing --after-pull-exec %q: %w", pullOptions.AfterPullExec, err)
+		}
+		if len(parsed) == 0 {
+			return errors.New("--after-pull-exec must not be empty")
+		}
+		afterPullExec = parsed
+	} else if pullOptions.IgnoreHookErrors {
+		return errors.New("--ignore-hook-errors option can only be specified with --after-pull-exec")
+	}
+
+	if pullOptions.LockDir != "" {
+		if err := os.MkdirAll(pullOptions.LockDir, 0o755); err != nil {
+			return fmt.Errorf("creating lock directory: %w", err)
+		}
+	}
+
+	if pullOptions.ConcurrentImages < 1 {
+		return errors.New("--concurrent-images must be at least 1")
+	}
+	if pullOptions.FailOnWarning && pullOptions.ConcurrentImages > 1 {
+		// Warnings are logged by the process, not by the pull.
+		return errors.New("--fail-on-warning option can not be specified with --concurrent-images greater than 1, the warnings of parallel pulls cannot be told apart")
+	}
+
 	if cmd.Flags().Changed("authfile") {
 		if err := auth.CheckAuthFile(pullOptions.Authfile); err != nil {
 			return err
 		}
 	}
+	if cmd.Flags().Changed("registries-conf") {
+		// The runtime only checks that the file exists, make sure that a
+		// malformed config is reported before anything is pulled.
+		sys := &types.SystemContext{SystemRegistriesConfPath: registry.PodmanConfig().RegistriesConf}
+		if _, err := sysregistriesv2.GetRegistries(sys); err != nil {
+			return fmt.Errorf("invalid --registries-conf: %w", err)
+		}
+	}
 	platform, err := cmd.Flags().GetString("platform")
 	if err != nil {
 		return err
@@ -185,6 +439,30 @@ func imagePull(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(pullOptions.PreferPlatformVariants) > 0 {
+		if pullOptions.Variant != "" {
+			return errors.New("--prefer-platform-variant option can not be specified with a variant in --variant or --platform")
+		}
+		if slices.Contains(pullOptions.PreferPlatformVariants, "") {
+			return errors.New("--prefer-platform-variant must not contain empty variants")
+		}
+	}
+
+	if pullOptions.EnvCredsCLI != "" {
+		if pullOptions.CredentialsCLI != "" {
+			return errors.New("--env-creds option can not be specified with --creds")
+		}
+		val, found := os.LookupEnv(pullOptions.EnvCredsCLI)
+		if !found || val == "" {
+			return fmt.Errorf("environment variable %s for --env-creds is not set", pullOptions.EnvCredsCLI)
+		}
+		// Do not pass the credentials on to any child processes.
+		if err := os.Unsetenv(pullOptions.EnvCredsCLI); err != nil {
+			return err
+		}
+		pullOptions.CredentialsCLI = val
+	}
+
 	if pullOptions.CredentialsCLI != "" {
 		creds, err := util.ParseRegistryCreds(pullOptions.CredentialsCLI)
 		if err != nil {
@@ -194,28 +472,550 @@ func imagePull(cmd *cobra.Command, args []string) error {
 		pullOptions.Password = creds.Password
 	}
 
+	if pullOptions.ExitCodeOnNoop < 0 || pullOptions.ExitCodeOnNoop > 255 {
+		return fmt.Errorf("--exit-code-on-noop must be between 0 and 255, got %d", pullOptions.ExitCodeOnNoop)
+	}
+
+	// Commands run for the pull get the environment the pull was run
+	// with, not the proxy settings of this process.
+	environ := os.Environ()
+	if err := setPullProxy(pullOptions.ProxyCLI, pullOptions.NoProxyCLI); err != nil {
+		return err
+	}
+
+	if len(pullOptions.AnnotateCLI) > 0 {
+		pullOptions.LocalAnnotations = make(map[string]string, len(pullOptions.AnnotateCLI))
+		for _, annotation := range pullOptions.AnnotateCLI {
+			key, value, ok := strings.Cut(annotation, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid annotation %q: must be in the form KEY=VALUE", annotation)
+			}
+			pullOptions.LocalAnnotations[key] = value
+		}
+	}
+
+	if pullOptions.MinFreeSpaceCLI != "" {
+		minFree, err := units.FromHumanSize(pullOptions.MinFreeSpaceCLI)
+		if err != nil {
+			return fmt.Errorf("parsing --min-free-space %q: %w", pullOptions.MinFreeSpaceCLI, err)
+		}
+		if minFree <= 0 {
+			return fmt.Errorf("--min-free-space must be greater than 0, got %q", pullOptions.MinFreeSpaceCLI)
+		}
+		pullOptions.MinFreeSpace = uint64(minFree)
+	}
+
+	if pullOptions.WaitForRegistryCLI != "" {
+		timeout, err := time.ParseDuration(pullOptions.WaitForRegistryCLI)
+		if err != nil {
+			return fmt.Errorf("parsing --wait-for-registry %q: %w", pullOptions.WaitForRegistryCLI, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("--wait-for-registry must be greater than 0, got %q", pullOptions.WaitForRegistryCLI)
+		}
+		pullOptions.WaitForRegistry = timeout
+	}
+
+	if pullOptions.VerifyLayersCLI != "" {
+		diffIDs, err := readDiffIDs(pullOptions.VerifyLayersCLI)
+		if err != nil {
+			return fmt.Errorf("reading --verify-layers: %w", err)
+		}
+		pullOptions.VerifyLayers = diffIDs
+	}
+
+	if pullOptions.NormalizeName && pullOptions.AllTags {
+		return errors.New("--normalize-name option can not be specified with --all-tags")
+	}
+
+	if pullOptions.SinceEventCLI != "" {
+		if !pullOptions.AllTags {
+			return errors.New("--since-event option can only be specified with --all-tags")
+		}
+		since, err := util.ParseInputTime(pullOptions.SinceEventCLI, true)
+		if err != nil {
+			return fmt.Errorf("parsing --since-event %q: %w", pullOptions.SinceEventCLI, err)
+		}
+		pullOptions.AllTagsSince = since
+	}
+
+	if pullOptions.RedirectAuthTo != "" && pullOptions.TokenCacheDir != "" {
+		return errors.New("--redirect-auth-to option can not be specified with --cache-dir")
+	}
+
+	if pullOptions.RepairLayers {
+		if pullOptions.AllTags {
+			return errors.New("--download-only-missing-layers option can not be specified with --all-tags")
+		}
+		if cmd.Flags().Changed("policy") {
+			return errors.New("--download-only-missing-layers option can not be specified with --policy")
+		}
+	}
+
+	pullPolicy, err := config.ParsePullPolicy(pullOptions.PolicyCLI)
+	if err != nil {
+		return err
+	}
+	pullOptions.PullPolicy = pullPolicy
+
 	decConfig, err := cli.DecryptConfig(pullOptions.DecryptionKeys)
 	if err != nil {
 		return fmt.Errorf("unable to obtain decryption config: %w", err)
 	}
 	pullOptions.OciDecryptConfig = decConfig
+	pullOptions.CollectWarnings = pullOptions.FailOnWarning
 
-	if !pullOptions.Quiet {
+	progress, err := pullProgress(cmd)
+	if err != nil {
+		return err
+	}
+	// Only progress bars are written by c/image, everything else is
+	// rendered here.
+	pullOptions.Quiet = progress != "fancy" && progress != "plain"
+	switch progress {
+	case "fancy":
 		pullOptions.Writer = os.Stderr
+	case "plain":
+		pullOptions.Writer = plainWriter{os.Stderr}
+	case "json":
+		pullOptions.JSONProgress = true
+	}
+
+	if pullOptions.Transport != "" {
+		transport := transports.Get(pullOptions.Transport)
+		if transport == nil {
+			return fmt.Errorf("invalid transport %q", pullOptions.Transport)
+		}
+		prefix := transport.Name() + ":"
+		if transport.Name() == docker.Transport.Name() {
+			prefix += "//"
+		}
+		prefixed := make([]string, len(args))
+		for i, arg := range args {
+			prefixed[i] = prefix + arg
+		}
+		args = prefixed
 	}
 
 	// Let's do all the remaining Yoga in the API to prevent us from
 	// scattering logic across (too) many parts of the code.
 	var errs utils.OutputErrors
-	for _, arg := range args {
-		pullReport, err := registry.ImageEngine().Pull(registry.GetContext(), arg, pullOptions.ImagePullOptions)
+	result := entities.ImagePullResult{Images: []entities.ImagePullResultImage{}}
+	ctx, cancel := context.WithCancel(registry.GetContext())
+	defer cancel()
+	results := pullImages(ctx, args, pullOptions.ConcurrentImages)
+	noop := true
+	var pulled, present int
+	for i, arg := range args {
+		if pullOptions.StopOnError && len(errs) > 0 {
+			// Abort the pulls that are still running or queued.
+			cancel()
+			break
+		}
+		// Results are processed in the order of the arguments, which
+		// keeps the output stable regardless of the concurrency.
+		<-results[i].done
+		pullReport, err := results[i].report, results[i].err
 		if err != nil {
+			if pullOptions.NoTruncErrors {
+				err = fullPullError(arg, err)
+			}
+			result.Images = append(result.Images, failedPullResult(arg, err))
+			errs = append(errs, err)
+			continue
+		}
+		if pullOptions.FailOnWarning && len(pullReport.Warnings) > 0 {
+			err := fmt.Errorf("pulling %s: warnings treated as errors: %s", arg, strings.Join(pullReport.Warnings, "; "))
+			result.Images = append(result.Images, failedPullResult(arg, err))
 			errs = append(errs, err)
 			continue
 		}
+		if len(afterPullExec) > 0 && !pullReport.CacheHit {
+			if err := runAfterPullExec(afterPullExec, environ, arg, pullReport.Images); err != nil {
+				if !pullOptions.IgnoreHookErrors {
+					result.Images = append(result.Images, failedPullResult(arg, err))
+					errs = append(errs, err)
+					continue
+				}
+				logrus.Warnf("%v", err)
+			}
+		}
+		noop = noop && pullReport.CacheHit
+		if pullOptions.SummaryOnly {
+			if pullReport.CacheHit {
+				present += len(pullReport.Images)
+			} else {
+				pulled += len(pullReport.Images)
+			}
+			continue
+		}
+		if pullOptions.QuietOnCacheHit && pullReport.CacheHit {
+			continue
+		}
+		for _, referrer := range pullReport.Referrers {
+			fmt.Fprintf(os.Stderr, "Pulled %s %s@%s\n", referrer.Type, referrer.Name, referrer.Digest)
+		}
+		if !pullOptions.Quiet {
+			for _, blob := range pullReport.Blobs {
+				fmt.Fprintf(os.Stderr, "Blob %s uses %s\n", blob.Digest, blob.Algorithm)
+			}
+		}
+		if pullReport.Replaced != "" {
+			fmt.Fprintf(os.Stderr, "Replaced image %s\n", pullReport.Replaced)
+		}
+		if pullReport.RepairedLayers > 0 {
+			fmt.Fprintf(os.Stderr, "Repaired %d missing layers\n", pullReport.RepairedLayers)
+		}
+		if pullOptions.JSON {
+			images, err := pullResultImages(arg, pullReport.Images)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for i := range images {
+				images[i].ResolvedName = pullReport.ResolvedName
+				images[i].RepairedLayers = pullReport.RepairedLayers
+			}
+			result.Images = append(result.Images, images...)
+			continue
+		}
+		if pullReport.ResolvedName != "" {
+			fmt.Println(pullReport.ResolvedName)
+		}
+		if pullOptions.ManifestOnly {
+			for _, m := range pullReport.Manifests {
+				fmt.Println(m)
+			}
+			continue
+		}
+		if pullOptions.RepoDigestOnly {
+			for _, img := range pullReport.Images {
+				digest, err := repoDigest(arg, img)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				fmt.Println(digest)
+			}
+			continue
+		}
 		for _, img := range pullReport.Images {
 			fmt.Println(img)
 		}
 	}
+	if pullOptions.JSON {
+		prettyJSON, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(prettyJSON))
+	}
+	if pullOptions.SummaryOnly {
+		fmt.Println(pullSummary(pulled, present, len(errs)))
+	}
+	if len(errs) == 0 && noop && pullOptions.ExitCodeOnNoop != 0 {
+		registry.SetExitCode(pullOptions.ExitCodeOnNoop)
+	}
 	return errs.PrintErrors()
 }
+
+// pullSummary returns the summary printed with --summary-only.
+func pullSummary(pulled, present, failed int) string {
+	images := "images"
+	if pulled == 1 {
+		images = "image"
+	}
+	return fmt.Sprintf("Pulled %d %s, %d already present, %d failed", pulled, images, present, failed)
+}
+
+// runAfterPullExec runs the --after-pull-exec command for each of the
+// images with the specified IDs pulled for arg, with the environment
+// environ.  The reference is passed as the last argument and, along with
+// the ID, in the environment.  Its output goes to stderr, so it does not
+// mix with the output of the pull.
+func runAfterPullExec(command, environ []string, arg string, ids []string) error {
+	for _, id := range ids {
+		hook := exec.Command(command[0], append(command[1:], arg)...)
+		hook.Env = append(slices.Clip(environ), "PODMAN_PULL_REFERENCE="+arg, "PODMAN_PULL_IMAGE_ID="+id)
+		hook.Stdout = os.Stderr
+		hook.Stderr = os.Stderr
+		if err := hook.Run(); err != nil {
+			return fmt.Errorf("running --after-pull-exec for %s: %w", arg, err)
+		}
+	}
+	return nil
+}
+
+// fullPullError prefixes err with the image it occurred for and adds the
+// code and details of registry errors, which are not part of their message.
+func fullPullError(image string, err error) error {
+	var details []string
+	var registryErr errcode.Error
+	if errors.As(err, &registryErr) {
+		details = append(details, "registry error code "+registryErr.Code.String())
+		if registryErr.Detail != nil {
+			if detail, jsonErr := json.Marshal(registryErr.Detail); jsonErr == nil {
+				details = append(details, "detail "+string(detail))
+			}
+		}
+	}
+	if len(details) == 0 {
+		return fmt.Errorf("%s: %w", image, err)
+	}
+	return fmt.Errorf("%s: %w (%s)", image, err, strings.Join(details, ", "))
+}
+
+// pullProgress resolves --progress and --quiet into the progress mode to
+// use: "none", "fancy", "plain" or "json".
+func pullProgress(cmd *cobra.Command) (string, error) {
+	progress := pullOptions.ProgressMode
+	switch progress {
+	case "none", "auto", "plain", "json":
+	case "ascii":
+		// The name of plain progress in older versions.
+		progress = "plain"
+	default:
+		return "", fmt.Errorf("invalid progress mode %q: must be \"none\", \"auto\", \"plain\" or \"json\"", progress)
+	}
+	if progress == "json" && registry.IsRemote() {
+		return "", errors.New("--progress json is not supported for remote clients")
+	}
+	if pullOptions.Quiet {
+		if progress != "none" && cmd.Flags().Changed("progress") {
+			return "", fmt.Errorf("--quiet option can not be specified with --progress %s", progress)
+		}
+		progress = "none"
+	}
+
+	if progress == "auto" {
+		progress = "fancy"
+		if os.Getenv("TERM") == "dumb" || !term.IsTerminal(int(os.Stderr.Fd())) {
+			progress = "plain"
+		}
+	}
+	return progress, nil
+}
+
+// printJSONProgress prints the progress events as JSON messages to stderr,
+// one per line, in the format used by the Docker-compatible API.  Each
+// message is written at once, so the messages of concurrent pulls do not
+// interleave within a line.
+func printJSONProgress(progress <-chan types.ProgressProperties) {
+	enc := json.NewEncoder(os.Stderr)
+	for e := range progress {
+		report := jsonmessage.JSONMessage{}
+		switch e.Event {
+		case types.ProgressEventNewArtifact:
+			report.Status = "Pulling fs layer"
+		case types.ProgressEventRead:
+			report.Status = "Downloading"
+			report.Progress = &jsonmessage.JSONProgress{
+				Current: int64(e.Offset),
+				Total:   e.Artifact.Size,
+			}
+		case types.ProgressEventSkipped:
+			report.Status = "Already exists"
+		case types.ProgressEventDone:
+			report.Status = "Download complete"
+		}
+		report.ID = e.Artifact.Digest.Encoded()
+		if err := enc.Encode(report); err != nil {
+			logrus.Warnf("Failed to json encode progress: %v", err)
+		}
+	}
+}
+
+// setPullProxy makes the registry clients of this process use the proxy
+// and no-proxy hosts.  containers/image uses http.ProxyFromEnvironment,
+// which reads the environment on first use, and has no option to set a
+// proxy, so the environment of the process is overridden before anything
+// is pulled.  Commands run by pull must be given the original environment.
+func setPullProxy(proxy string, noProxy []string) error {
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid --proxy %q: %w", proxy, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid --proxy %q: scheme must be http, https or socks5", proxy)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid --proxy %q: missing host", proxy)
+		}
+		for _, env := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+			if err := os.Setenv(env, proxy); err != nil {
+				return err
+			}
+		}
+		logrus.Debugf("Pulling through proxy %s", u.Redacted())
+	}
+	if len(noProxy) > 0 {
+		hosts := strings.Join(noProxy, ",")
+		if err := os.Setenv("NO_PROXY", hosts); err != nil {
+			return err
+		}
+		logrus.Debugf("Not using a proxy for %s", hosts)
+	}
+	return nil
+}
+
+// readDiffIDs reads the diff IDs listed in the file at path, one per line.
+// Empty lines and lines starting with # are ignored.
+func readDiffIDs(path string) ([]digest.Digest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var diffIDs []digest.Digest
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		diffID, err := digest.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid diff ID %q: %w", line, err)
+		}
+		diffIDs = append(diffIDs, diffID)
+	}
+	if len(diffIDs) == 0 {
+		return nil, fmt.Errorf("no diff IDs in %s", path)
+	}
+	return diffIDs, nil
+}
+
+// pullImages starts pulling the specified images with at most concurrency
+// pulls running in parallel.  The returned results are in the order of args.
+// Images not yet started when ctx is canceled are not pulled.
+func pullImages(ctx context.Context, args []string, concurrency uint) []*pullResult {
+	results := make([]*pullResult, len(args))
+	for i := range results {
+		results[i] = &pullResult{done: make(chan struct{})}
+	}
+	sem := make(chan struct{}, concurrency)
+	go func() {
+		for i, arg := range args {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(result *pullResult, arg string) {
+				defer func() {
+					<-sem
+					close(result.done)
+				}()
+				if pullOptions.LockDir != "" {
+					unlock, err := lockPull(pullOptions.LockDir, arg)
+					if err != nil {
+						result.err = err
+						return
+					}
+					defer unlock()
+				}
+				result.report, result.err = registry.ImageEngine().Pull(ctx, arg, imagePullOptions())
+			}(results[i], arg)
+		}
+	}()
+	return results
+}
+
+// imagePullOptions returns the options for a single pull.  The fields
+// referring to shared data are copied, so that concurrent pulls do not share
+// any state, and each pull gets its own progress channel.
+func imagePullOptions() entities.ImagePullOptions {
+	opts := pullOptions.ImagePullOptions
+	if opts.Retry != nil {
+		retry := *opts.Retry
+		opts.Retry = &retry
+	}
+	opts.LocalAnnotations = maps.Clone(opts.LocalAnnotations)
+	opts.PrefetchReferrers = slices.Clone(opts.PrefetchReferrers)
+	opts.PreferPlatformVariants = slices.Clone(opts.PreferPlatformVariants)
+	opts.VerifyLayers = slices.Clone(opts.VerifyLayers)
+	if pullOptions.JSONProgress {
+		progress := make(chan types.ProgressProperties)
+		opts.Progress = progress
+		// The channel is never closed as canceled pulls may still
+		// report progress; the goroutine ends with the process.
+		go printJSONProgress(progress)
+	}
+	return opts
+}
+
+// pullLockName returns the name of the lock file for pulling arg.  Names of
+// the same image are normalized to the same file where possible.
+func pullLockName(arg string) string {
+	name := strings.TrimPrefix(arg, "docker://")
+	if named, err := reference.ParseDockerRef(name); err == nil {
+		name = named.String()
+	}
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+	if len(name) > 200 {
+		// Keep the name within the limits of file systems.
+		sum := sha256.Sum256([]byte(name))
+		name = hex.EncodeToString(sum[:])
+	}
+	return name + ".lock"
+}
+
+// failedPullResult describes the failed pull of arg for --json.
+func failedPullResult(arg string, err error) entities.ImagePullResultImage {
+	return entities.ImagePullResultImage{Reference: arg, Status: "failed", Error: err.Error()}
+}
+
+// pullResultImages describes the images with the specified IDs pulled for
+// arg for --json.  The details are inspected through the image engine, so
+// local and remote pulls report the same.
+func pullResultImages(arg string, ids []string) ([]entities.ImagePullResultImage, error) {
+	reports, errs, err := registry.ImageEngine().Inspect(registry.GetContext(), ids, entities.InspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	images := make([]entities.ImagePullResultImage, 0, len(reports))
+	for _, report := range reports {
+		images = append(images, entities.ImagePullResultImage{
+			Reference: arg,
+			Status:    "pulled",
+			ID:        report.ID,
+			Names:     report.RepoTags,
+			Digest:    report.Digest.String(),
+			Size:      report.Size,
+		})
+	}
+	return images, nil
+}
+
+// repoDigest returns a repo digest of the image with the specified ID,
+// preferring one in the repository of the pulled reference.
+func repoDigest(arg, id string) (string, error) {
+	reports, errs, err := registry.ImageEngine().Inspect(registry.GetContext(), []string{id}, entities.InspectOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(errs) > 0 {
+		return "", errs[0]
+	}
+	digests := reports[0].RepoDigests
+	if len(digests) == 0 {
+		return "", fmt.Errorf("image %s has no repo digest", id)
+	}
+	if named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(arg, "docker://")); err == nil {
+		for _, digest := range digests {
+			if strings.HasPrefix(digest, named.Name()+"@") {
+				return digest, nil
+			}
+		}
+	}
+	return digests[0], nil
+}
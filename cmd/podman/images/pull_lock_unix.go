@@ -0,0 +1,48 @@
+//go:build !windows
+
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// lockPull takes an exclusive flock(2) on the lock file of the image arg in
+// dir, waiting while another pull of the image holds it.  The returned
+// function removes the file and releases the lock.
+func lockPull(dir, arg string) (func(), error) {
+	path := filepath.Join(dir, pullLockName(arg))
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening lock file: %w", err)
+		}
+		if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+			logrus.Debugf("Waiting for another pull of %s holding %s", arg, path)
+			if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("locking %s: %w", path, err)
+			}
+		}
+		// The previous holder removes the file before releasing the
+		// lock, so the lock is only valid if the file is still in place.
+		locked, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(path); err == nil && os.SameFile(locked, current) {
+			return func() {
+				if err := os.Remove(path); err != nil {
+					logrus.Debugf("Removing lock file: %v", err)
+				}
+				f.Close()
+			}, nil
+		}
+		f.Close()
+	}
+}
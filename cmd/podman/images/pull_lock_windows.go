@@ -0,0 +1,7 @@
+package images
+
+import "errors"
+
+func lockPull(dir, arg string) (func(), error) {
+	return nil, errors.New("--lock-dir is not supported on Windows")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/containers/podman/v5/cmd/podman/registry"
 	"github.com/containers/podman/v5/cmd/podman/validate"
 	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
 )
@@ -43,6 +44,7 @@ var (
 var (
 	inFormat string
 	debug    bool
+	detail   bool
 )
 
 func init() {
@@ -64,18 +66,21 @@ func infoFlags(cmd *cobra.Command) {
 	flags.BoolVarP(&debug, "debug", "D", false, "Display additional debug information")
 	_ = flags.MarkHidden("debug") // It's a NOP since Podman version 2.0
 
+	flags.BoolVar(&detail, "detail", false, "Include information that is expensive to gather, such as the per-CPU utilization")
+
 	formatFlagName := "format"
 	flags.StringVarP(&inFormat, formatFlagName, "f", "", "Change the output format to JSON or a Go template")
 	_ = cmd.RegisterFlagCompletionFunc(formatFlagName, common.AutocompleteFormat(&define.Info{}))
 }
 
 func info(cmd *cobra.Command, args []string) error {
-	info, err := registry.ContainerEngine().Info(registry.GetContext())
+	info, err := registry.ContainerEngine().Info(registry.GetContext(), entities.SystemInfoOptions{Detail: detail})
 	if err != nil {
 		return err
 	}
 
 	info.Host.ServiceIsRemote = registry.IsRemote()
+	info.Machine = machineInfo()
 
 	switch {
 	case report.IsJSON(inFormat):
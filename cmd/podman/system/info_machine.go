@@ -0,0 +1,58 @@
+//go:build amd64 || arm64
+
+package system
+
+import (
+	"strings"
+
+	"github.com/containers/podman/v5/cmd/podman/registry"
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/machine/env"
+	"github.com/containers/podman/v5/pkg/machine/provider"
+	"github.com/containers/podman/v5/pkg/machine/vmconfigs"
+	"github.com/sirupsen/logrus"
+)
+
+// machineInfo returns information about the podman machine the active
+// connection targets, or nil if it does not target one.
+func machineInfo() *define.MachineInfo {
+	podmanConfig := registry.PodmanConfig()
+	if !podmanConfig.MachineMode {
+		return nil
+	}
+	connections, err := podmanConfig.ContainersConfDefaultsRO.GetAllConnections()
+	if err != nil {
+		logrus.Debugf("Reading system connections: %v", err)
+		return nil
+	}
+	for _, con := range connections {
+		if !con.IsMachine || con.URI != podmanConfig.URI {
+			continue
+		}
+		// Machines have a connection named after them and one with a
+		// -root suffix for the rootful service.
+		info := &define.MachineInfo{Name: strings.TrimSuffix(con.Name, "-root"), Connection: con.Name}
+		for _, vmType := range provider.SupportedProviders() {
+			dirs, err := env.GetMachineDirs(vmType)
+			if err != nil {
+				logrus.Debugf("Getting %s machine directories: %v", vmType, err)
+				continue
+			}
+			mc, err := vmconfigs.LoadMachineByName(info.Name, dirs)
+			if err != nil {
+				continue
+			}
+			info.Provider = vmType.String()
+			info.Rootful = mc.HostUser.Rootful
+			info.CPUs = mc.Resources.CPUs
+			info.Memory = uint64(mc.Resources.Memory.ToBytes())
+			info.DiskSize = uint64(mc.Resources.DiskSize.ToBytes())
+			break
+		}
+		if info.Provider == "" {
+			logrus.Debugf("No configuration found for machine %s", info.Name)
+		}
+		return info
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !amd64 && !arm64
+
+package system
+
+import "github.com/containers/podman/v5/libpod/define"
+
+func machineInfo() *define.MachineInfo {
+	return nil
+}
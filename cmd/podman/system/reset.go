@@ -12,6 +12,7 @@ import (
 	"github.com/containers/common/pkg/completion"
 	"github.com/containers/podman/v5/cmd/podman/registry"
 	"github.com/containers/podman/v5/cmd/podman/validate"
+	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -61,7 +62,7 @@ func reset(cmd *cobra.Command, args []string) {
         - all machines
         - all volumes`)
 
-		info, _ := registry.ContainerEngine().Info(registry.Context())
+		info, _ := registry.ContainerEngine().Info(registry.Context(), entities.SystemInfoOptions{})
 		// lets not hard fail in case of an error
 		if info != nil {
 			fmt.Printf("        - the graphRoot directory: %q\n", info.Store.GraphRoot)
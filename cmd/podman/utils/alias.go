@@ -42,3 +42,11 @@ func TimeoutAliasFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
 	}
 	return pflag.NormalizedName(name)
 }
+
+// StopOnErrorAliasFlags is a function to handle the --no-keep-going alias of --stop-on-error
+func StopOnErrorAliasFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "no-keep-going" {
+		name = "stop-on-error"
+	}
+	return pflag.NormalizedName(name)
+}
@@ -124,7 +124,7 @@ func IsCheckpointImage(ctx context.Context, namesOrIDs []string) (bool, error) {
 	}
 	imgID := imgData[0].ID
 
-	hostInfo, err := registry.ContainerEngine().Info(ctx)
+	hostInfo, err := registry.ContainerEngine().Info(ctx, entities.SystemInfoOptions{})
 	if err != nil {
 		return false, err
 	}
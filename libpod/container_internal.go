@@ -527,6 +527,7 @@ func (c *Container) setupStorage(ctx context.Context) error {
 		}
 		return fmt.Errorf("creating container storage: %w", containerInfoErr)
 	}
+	c.runtime.recordStoreWriter()
 
 	// Only reconfig IDMappings if layer was mounted from storage.
 	// If it's an external overlay do not reset IDmappings.
@@ -1,6 +1,8 @@
 package define
 
 import (
+	"time"
+
 	"github.com/containers/common/libnetwork/types"
 	"github.com/containers/storage/pkg/idtools"
 )
@@ -9,63 +11,316 @@ import (
 // running libpod/podman
 // swagger:model LibpodInfo
 type Info struct {
-	Host       *HostInfo              `json:"host"`
-	Store      *StoreInfo             `json:"store"`
-	Registries map[string]interface{} `json:"registries"`
-	Plugins    Plugins                `json:"plugins"`
-	Version    Version                `json:"version"`
+	Host              *HostInfo              `json:"host"`
+	Store             *StoreInfo             `json:"store"`
+	Registries        map[string]interface{} `json:"registries"`
+	Plugins           Plugins                `json:"plugins"`
+	Version           Version                `json:"version"`
+	ContainerDefaults *ContainerDefaultsInfo `json:"containerDefaults,omitempty"`
+	Pull              *PullInfo              `json:"pull,omitempty"`
+	// RegistryConfigPaths are the registries.conf files the registry
+	// configuration was loaded from, in load order.  Later files override
+	// settings of earlier ones.
+	RegistryConfigPaths []string `json:"registryConfigPaths,omitempty"`
+	// Machine describes the podman machine VM the client is connected
+	// to.  Only set by the client, when the connection targets a machine.
+	Machine *MachineInfo `json:"machine,omitempty"`
+	// ServiceRuntime describes the resource usage of the API service.
+	// Only set by the service.
+	ServiceRuntime *ServiceRuntimeInfo `json:"serviceRuntime,omitempty"`
+	// SecurityDefaults describes how the containers.conf defaults differ
+	// from the defaults of a bare OCI runtime.  Nil on platforms without
+	// capabilities.
+	SecurityDefaults *SecurityDefaultsInfo `json:"securityDefaults,omitempty"`
+	// ContainerDNS describes the DNS defaults from containers.conf
+	// applied to the /etc/resolv.conf of every container
+	ContainerDNS *ContainerDNSInfo `json:"containerDNS,omitempty"`
+	// Locks describes the locks of containers, pods and volumes
+	Locks *LockInfo `json:"locks,omitempty"`
+}
+
+// LockInfo describes the backend of the locks of containers, pods and
+// volumes and, for the API service, how they were acquired
+type LockInfo struct {
+	// Type is the lock backend, "shm" or "file", as set by lock_type
+	Type string `json:"type"`
+	// Path is the shared memory segment or the directory of the locks
+	Path string `json:"path"`
+	// NumLocks is the number of locks of the shm backend, as set by
+	// num_locks.  The file backend is not limited.
+	NumLocks uint32 `json:"numLocks"`
+	// Acquisitions is the number of locks acquired by the service since
+	// it started.  Only set by the service.
+	Acquisitions *uint64 `json:"acquisitions,omitempty"`
+	// MaxWait is the longest time the service waited to acquire a lock.
+	// Only set by the service.
+	MaxWait time.Duration `json:"maxWait,omitempty"`
+}
+
+// ContainerDNSInfo describes the dns_servers, dns_searches and dns_options
+// of containers.conf.  Each replaces the corresponding entries of the
+// resolv.conf of the host, or of the network, in the /etc/resolv.conf of
+// containers unless it is empty or overridden with --dns, --dns-search and
+// --dns-option.
+type ContainerDNSInfo struct {
+	Servers  []string `json:"servers"`
+	Searches []string `json:"searches"`
+	Options  []string `json:"options"`
+}
+
+// SecurityDefaultsInfo describes how the default capabilities and ulimits
+// of containers differ from the default configuration generated by the OCI
+// runtimes (runc spec, crun spec)
+type SecurityDefaultsInfo struct {
+	// AddedCapabilities are the default capabilities of containers that
+	// the OCI runtime defaults do not include
+	AddedCapabilities []string `json:"addedCapabilities"`
+	// RemovedCapabilities are the capabilities of the OCI runtime
+	// defaults that containers do not get by default
+	RemovedCapabilities []string `json:"removedCapabilities"`
+	// Ulimits are the ulimits whose defaults differ
+	Ulimits []UlimitDiffInfo `json:"ulimits"`
+}
+
+// UlimitDiffInfo describes a ulimit whose default differs between Podman
+// and the OCI runtime defaults
+type UlimitDiffInfo struct {
+	// Name of the ulimit, e.g. nofile
+	Name string `json:"name"`
+	// OCI is the soft:hard limit of the OCI runtime defaults, empty if
+	// they do not set it
+	OCI string `json:"oci"`
+	// Podman is the soft:hard limit set by default_ulimits, or "inherited"
+	// if it is not set and containers get the limit of the Podman process
+	Podman string `json:"podman"`
+}
+
+// ServiceRuntimeInfo describes the resource usage of the Podman API
+// service process, for watching a long-running service for leaks
+type ServiceRuntimeInfo struct {
+	// Goroutines is the number of goroutines of the service
+	Goroutines int `json:"goroutines"`
+	// OpenFDs is the number of open file descriptors of the service,
+	// -1 if it cannot be determined
+	OpenFDs int `json:"openFDs"`
+	// HeapAlloc is the size in bytes of the allocated heap objects
+	HeapAlloc uint64 `json:"heapAlloc"`
+	// HeapSys is the size in bytes of the heap memory obtained from the
+	// operating system
+	HeapSys uint64 `json:"heapSys"`
+}
+
+// MachineInfo describes a podman machine VM running the Podman service
+type MachineInfo struct {
+	// Name of the machine
+	Name string `json:"name"`
+	// Connection is the name of the system connection used
+	Connection string `json:"connection"`
+	// Provider is the VM provider, e.g. qemu, applehv, hyperv or wsl
+	Provider string `json:"provider"`
+	// Rootful is true if the machine runs the Podman service as root by
+	// default
+	Rootful bool `json:"rootful"`
+	// CPUs assigned to the VM
+	CPUs uint64 `json:"cpus"`
+	// Memory assigned to the VM, in bytes
+	Memory uint64 `json:"memory"`
+	// DiskSize of the VM, in bytes
+	DiskSize uint64 `json:"diskSize"`
+}
+
+// PullInfo describes the configuration affecting image pulls
+type PullInfo struct {
+	// ShortNameMode is the short-name resolution mode, "enforcing",
+	// "permissive" or "disabled"
+	ShortNameMode string `json:"shortNameMode"`
+	// UnqualifiedSearchRegistries are the registries short names are
+	// resolved against, in order
+	UnqualifiedSearchRegistries []string `json:"unqualifiedSearchRegistries"`
+	// MaxParallelDownloads is the maximum number of layers pulled in
+	// parallel, as set by image_parallel_copies in containers.conf
+	MaxParallelDownloads uint `json:"maxParallelDownloads"`
+	// DefaultTransport is the transport of images pulled by a name
+	// without one.  Short names are resolved against
+	// UnqualifiedSearchRegistries with it.
+	DefaultTransport string `json:"defaultTransport"`
+	// ImageDefaultTransport is image_default_transport in containers.conf.
+	// It does not affect podman pull, which always uses DefaultTransport.
+	ImageDefaultTransport string `json:"imageDefaultTransport"`
+	// CompatAPIEnforceDockerHub is true if short names pulled through the
+	// Docker-compatible API are always resolved to docker.io, regardless
+	// of UnqualifiedSearchRegistries, as set by
+	// compat_api_enforce_docker_hub in containers.conf.  It does not
+	// affect podman pull or the libpod API.
+	CompatAPIEnforceDockerHub bool `json:"compatAPIEnforceDockerHub"`
+}
+
+// ContainerDefaultsInfo describes the defaults from containers.conf applied
+// to new containers
+type ContainerDefaultsInfo struct {
+	// CgroupConf are the cgroup files written for every container
+	CgroupConf []string `json:"cgroupConf"`
+	// Capabilities are the default capabilities of containers
+	Capabilities []string `json:"capabilities"`
+	// ImageVolumeMode is how the VOLUME directives of images are handled:
+	// "anonymous" creates anonymous volumes, "tmpfs" mounts a tmpfs and
+	// "ignore" ignores them, as set by image_volume_mode
+	ImageVolumeMode string `json:"imageVolumeMode"`
+	// DefaultSysctls are the sysctls set in every container, unless the
+	// container shares the namespace the sysctl belongs to with the host
+	DefaultSysctls map[string]string `json:"defaultSysctls"`
+	// NetworkMode is the network mode new containers get if none is
+	// given, such as "bridge", "pasta", "slirp4netns", "host" or "none"
+	NetworkMode string `json:"networkMode"`
+	// PidsLimit is the default pids limit, 0 or less means unlimited
+	PidsLimit int64 `json:"pidsLimit"`
+	// TZ is the default timezone of containers, "local" for the timezone
+	// of the host.  Empty means containers keep the timezone of the image.
+	TZ string `json:"tz"`
+	// Ulimits are the default ulimits of containers, as "name=soft:hard"
+	Ulimits []string `json:"ulimits"`
 }
 
 // SecurityInfo describes the libpod host
 type SecurityInfo struct {
 	AppArmorEnabled     bool   `json:"apparmorEnabled"`
 	DefaultCapabilities string `json:"capabilities"`
-	Rootless            bool   `json:"rootless"`
-	SECCOMPEnabled      bool   `json:"seccompEnabled"`
-	SECCOMPProfilePath  string `json:"seccompProfilePath"`
-	SELinuxEnabled      bool   `json:"selinuxEnabled"`
+	// FIPSEnabled is true if the kernel runs in FIPS mode, as reported by
+	// /proc/sys/crypto/fips_enabled
+	FIPSEnabled bool `json:"fipsEnabled"`
+	// KeyringAvailable is true when the kernel keyring syscalls can be used
+	KeyringAvailable   bool   `json:"keyringAvailable"`
+	Rootless           bool   `json:"rootless"`
+	SECCOMPEnabled     bool   `json:"seccompEnabled"`
+	SECCOMPProfilePath string `json:"seccompProfilePath"`
+	SELinuxEnabled     bool   `json:"selinuxEnabled"`
+	// UserNS describes the user namespace Podman runs in.  Nil on
+	// platforms without user namespaces.
+	UserNS *UserNSInfo `json:"userNS,omitempty"`
+	// DefaultCapabilitiesList are the capabilities of DefaultCapabilities
+	// as a list, prefixed with CAP_
+	DefaultCapabilitiesList []string `json:"capabilitiesList"`
+	// DroppedCapabilities are the known capabilities that containers do
+	// not get by default
+	DroppedCapabilities []string `json:"droppedCapabilities"`
+	// DefaultMountsFile is the mounts.conf file whose host paths are
+	// mounted into every container, if any
+	DefaultMountsFile string `json:"defaultMountsFile,omitempty"`
+	// DefaultMounts is the number of host paths DefaultMountsFile mounts
+	// into every container
+	DefaultMounts int `json:"defaultMounts"`
+}
+
+// UserNSInfo describes the user namespace of the Podman process
+type UserNSInfo struct {
+	// Mode is "host" outside of a user namespace, "rootless" in the user
+	// namespace set up for a rootless user and "nested" when running as
+	// root in a user namespace created by someone else, e.g. inside a
+	// container, where root only has privileges over the mapped IDs
+	Mode string `json:"mode"`
+	// UIDMap and GIDMap are the ID mappings of the user namespace
+	UIDMap []idtools.IDMap `json:"uidmap"`
+	GIDMap []idtools.IDMap `json:"gidmap"`
 }
 
 // HostInfo describes the libpod host
 type HostInfo struct {
-	Arch               string            `json:"arch"`
-	BuildahVersion     string            `json:"buildahVersion"`
-	CgroupManager      string            `json:"cgroupManager"`
-	CgroupsVersion     string            `json:"cgroupVersion"`
-	CgroupControllers  []string          `json:"cgroupControllers"`
-	Conmon             *ConmonInfo       `json:"conmon"`
-	CPUs               int               `json:"cpus"`
-	CPUUtilization     *CPUUsage         `json:"cpuUtilization"`
-	DatabaseBackend    string            `json:"databaseBackend"`
-	Distribution       DistributionInfo  `json:"distribution"`
-	EventLogger        string            `json:"eventLogger"`
-	FreeLocks          *uint32           `json:"freeLocks,omitempty"`
-	Hostname           string            `json:"hostname"`
-	IDMappings         IDMappings        `json:"idMappings,omitempty"`
-	Kernel             string            `json:"kernel"`
-	LogDriver          string            `json:"logDriver"`
-	MemFree            int64             `json:"memFree"`
-	MemTotal           int64             `json:"memTotal"`
-	NetworkBackend     string            `json:"networkBackend"`
-	NetworkBackendInfo types.NetworkInfo `json:"networkBackendInfo"`
-	OCIRuntime         *OCIRuntimeInfo   `json:"ociRuntime"`
-	OS                 string            `json:"os"`
+	Arch              string   `json:"arch"`
+	BuildahVersion    string   `json:"buildahVersion"`
+	CgroupManager     string   `json:"cgroupManager"`
+	CgroupsVersion    string   `json:"cgroupVersion"`
+	CgroupControllers []string `json:"cgroupControllers"`
+	// ClockSynchronized is true if the kernel reports the system clock
+	// as synchronized, e.g. by NTP.  Nil if it cannot be determined.
+	ClockSynchronized *bool       `json:"clockSynchronized,omitempty"`
+	Conmon            *ConmonInfo `json:"conmon"`
+	CPUs              int         `json:"cpus"`
+	CPUUtilization    *CPUUsage   `json:"cpuUtilization"`
+	// CRIU describes the CRIU installation used for checkpoint/restore.
+	// Nil on platforms without checkpoint/restore support.
+	CRIU            *CRIUInfo        `json:"criu,omitempty"`
+	DatabaseBackend string           `json:"databaseBackend"`
+	Distribution    DistributionInfo `json:"distribution"`
+	EventLogger     string           `json:"eventLogger"`
+	FreeLocks       *uint32          `json:"freeLocks,omitempty"`
+	// HelperBinaries describes where the helper binaries used by Podman were found
+	HelperBinaries []HelperBinaryInfo `json:"helperBinaries,omitempty"`
+	Hostname       string             `json:"hostname"`
+	IDMappings     IDMappings         `json:"idMappings,omitempty"`
+	// IDMappedMountsSupported is true when the kernel supports idmapped
+	// mounts and Podman has the privileges to create them, so mounts with
+	// the idmap option are expected to work on file systems supporting them
+	IDMappedMountsSupported bool              `json:"idMappedMountsSupported"`
+	Kernel                  string            `json:"kernel"`
+	LogDriver               string            `json:"logDriver"`
+	MemFree                 int64             `json:"memFree"`
+	MemTotal                int64             `json:"memTotal"`
+	NetworkBackend          string            `json:"networkBackend"`
+	NetworkBackendInfo      types.NetworkInfo `json:"networkBackendInfo"`
+	OCIRuntime              *OCIRuntimeInfo   `json:"ociRuntime"`
+	OS                      string            `json:"os"`
 	// RemoteSocket returns the UNIX domain socket the Podman service is listening on
 	RemoteSocket *RemoteSocket `json:"remoteSocket,omitempty"`
+	// RootlessIDMappingMode is "full" when a subordinate ID range is mapped,
+	// "single" when only the user's own ID is mapped and "none" otherwise.
+	// Only set for rootless users.
+	RootlessIDMappingMode string `json:"rootlessIDMappingMode,omitempty"`
 	// RootlessNetworkCmd returns the default rootless network command (slirp4netns or pasta)
-	RootlessNetworkCmd string                 `json:"rootlessNetworkCmd"`
-	RuntimeInfo        map[string]interface{} `json:"runtimeInfo,omitempty"`
+	RootlessNetworkCmd string `json:"rootlessNetworkCmd"`
+	// RootlessNetworkCmdSource is where RootlessNetworkCmd was resolved
+	// from ("config" or "default").  A configured value that equals the
+	// built-in default is reported as "default".
+	RootlessNetworkCmdSource string `json:"rootlessNetworkCmdSource,omitempty"`
+	// RootlessNetworkCmdReason explains the selection of
+	// RootlessNetworkCmd, including whether its binary is missing
+	RootlessNetworkCmdReason string `json:"rootlessNetworkCmdReason,omitempty"`
+	// RootlessPortForwarder is the component forwarding published ports
+	// with the default rootless network command: pasta, slirp4netns or
+	// rootlessport
+	RootlessPortForwarder string                 `json:"rootlessPortForwarder,omitempty"`
+	RuntimeInfo           map[string]interface{} `json:"runtimeInfo,omitempty"`
 	// ServiceIsRemote is true when the podman/libpod service is remote to the client
 	ServiceIsRemote bool         `json:"serviceIsRemote"`
 	Security        SecurityInfo `json:"security"`
 	Slirp4NetNS     SlirpInfo    `json:"slirp4netns,omitempty"`
 	Pasta           PastaInfo    `json:"pasta,omitempty"`
 
-	SwapFree  int64  `json:"swapFree"`
-	SwapTotal int64  `json:"swapTotal"`
-	Uptime    string `json:"uptime"`
-	Variant   string `json:"variant"`
-	Linkmode  string `json:"linkmode"`
+	SwapFree  int64 `json:"swapFree"`
+	SwapTotal int64 `json:"swapTotal"`
+	// SystemdUnit describes the systemd service unit Podman is running
+	// in, if any
+	SystemdUnit *SystemdUnitInfo `json:"systemdUnit,omitempty"`
+	// Timezone of the host, e.g. Europe/Berlin, as set by $TZ or
+	// /etc/localtime
+	Timezone string `json:"timezone"`
+	Uptime   string `json:"uptime"`
+	Variant  string `json:"variant"`
+	Linkmode string `json:"linkmode"`
+	// EventLogFile is the file events are written to with the file
+	// event logger
+	EventLogFile string `json:"eventLogFile,omitempty"`
+	// EventLogMaxSize is the size in bytes at which the event log file
+	// is rotated, 0 if it is never rotated
+	EventLogMaxSize uint64 `json:"eventLogMaxSize,omitempty"`
+	// EventLogSize is the current size in bytes of the event log file
+	EventLogSize int64 `json:"eventLogSize,omitempty"`
+	// NetworkPlugins describes the plugin directories of the network
+	// backend and the plugins found in them
+	NetworkPlugins *NetworkPluginsInfo `json:"networkPlugins,omitempty"`
+	// Pids describes how many PIDs are in use and available for
+	// containers.  Nil on platforms other than Linux.
+	Pids *PidsInfo `json:"pids,omitempty"`
+	// Hooks describes the OCI hooks run for containers.  Nil if no hooks
+	// directories are used.
+	Hooks *HooksInfo `json:"hooks,omitempty"`
+	// CgroupHybrid is true if the host mounts the cgroup v2 hierarchy next
+	// to the cgroup v1 hierarchies (systemd's hybrid mode).  CgroupsVersion
+	// reports v1 in that case.
+	CgroupHybrid bool `json:"cgroupHybrid"`
+	// RLimitNofileSoft and RLimitNofileHard are the limits on open file
+	// descriptors (RLIMIT_NOFILE) of the Podman process, 0 if unknown
+	RLimitNofileSoft uint64 `json:"rlimitNofileSoft"`
+	RLimitNofileHard uint64 `json:"rlimitNofileHard"`
 }
 
 // RemoteSocket describes information about the API socket
@@ -79,6 +334,9 @@ type SlirpInfo struct {
 	Executable string `json:"executable"`
 	Package    string `json:"package"`
 	Version    string `json:"version"`
+	// Sandboxed is set if slirp4netns is started with --enable-sandbox
+	// or --enable-seccomp
+	Sandboxed bool `json:"sandboxed"`
 }
 
 // PastaInfo describes the pasta executable that is being used
@@ -86,6 +344,32 @@ type PastaInfo struct {
 	Executable string `json:"executable"`
 	Package    string `json:"package"`
 	Version    string `json:"version"`
+	// Sandboxed is set if pasta runs in its own seccomp and namespace
+	// sandbox, which it always does
+	Sandboxed bool `json:"sandboxed"`
+}
+
+// NetworkPluginsInfo describes the configured plugin directories of the
+// network backend, cni_plugin_dirs or netavark_plugin_dirs
+type NetworkPluginsInfo struct {
+	// Dirs are the directories searched for plugins, in order
+	Dirs []string `json:"dirs"`
+	// Plugins are the paths of the plugin binaries found in Dirs.  A
+	// plugin present in several directories is only listed for the
+	// first one, which is the one that is used.
+	Plugins []string `json:"plugins"`
+}
+
+// HelperBinaryInfo describes the lookup of a helper binary in the
+// configured helper_binaries_dir
+type HelperBinaryInfo struct {
+	Name string `json:"name"`
+	// SearchDirs are the directories searched for the binary, in order
+	SearchDirs []string `json:"searchDirs"`
+	// SearchPath is true when $PATH is consulted after SearchDirs
+	SearchPath bool `json:"searchPath"`
+	// Path is the resolved path of the binary, empty if it was not found
+	Path string `json:"path"`
 }
 
 // IDMappings describe the GID and UID mappings
@@ -109,6 +393,70 @@ type ConmonInfo struct {
 	Version string `json:"version"`
 }
 
+// CRIUInfo describes the CRIU binary used to checkpoint and restore containers
+type CRIUInfo struct {
+	// Path of the CRIU binary, empty if it was not found
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Functional is true if CRIU could be queried for its version and
+	// is recent enough for checkpoint/restore
+	Functional bool `json:"functional"`
+	// Error explains why CRIU is not functional
+	Error string `json:"error,omitempty"`
+}
+
+// SystemdUnitInfo describes the systemd service unit the Podman process is
+// running in
+type SystemdUnitInfo struct {
+	// Name of the service unit, derived from the cgroup of the process.
+	// Empty if the process was moved out of the cgroup of the unit.
+	Name string `json:"name,omitempty"`
+	// InvocationID is the $INVOCATION_ID set by systemd for the unit
+	InvocationID string `json:"invocationID,omitempty"`
+}
+
+// HooksInfo describes the hooks directories and the hooks in them that run
+// for containers
+type HooksInfo struct {
+	// Dirs are the hooks directories, in increasing order of precedence
+	Dirs []string `json:"dirs"`
+	// Implicit is true if no hooks_dir is configured and the deprecated
+	// default directories are used.  Hooks in them do not override each
+	// other.
+	Implicit bool `json:"implicit"`
+	// Hooks are the effective hooks, in the order they are applied
+	Hooks []HookInfo `json:"hooks"`
+}
+
+// HookInfo describes an effective OCI hook
+type HookInfo struct {
+	// Name is the file name of the hook, which identifies it across
+	// directories
+	Name string `json:"name"`
+	// Path is the hook file that is used
+	Path string `json:"path"`
+	// Stages are the stages the hook runs in
+	Stages []string `json:"stages"`
+	// Overrides are the hook files with the same name in directories
+	// of lower precedence, which are not used
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// PidsInfo describes the PIDs in use on the host and the limits on them
+type PidsInfo struct {
+	// PidMax is kernel.pid_max, the highest PID the kernel assigns
+	PidMax int64 `json:"pidMax"`
+	// InUse is the number of PIDs in use on the host.  Every thread
+	// uses a PID, so this counts threads rather than processes.
+	InUse int64 `json:"inUse"`
+	// CgroupLimit is the lowest pids.max of the cgroup of the session
+	// and its ancestors, 0 if unlimited.  Only set for rootless Podman
+	// on cgroup v2, where containers are created in the session.
+	CgroupLimit int64 `json:"cgroupLimit,omitempty"`
+	// CgroupCurrent is pids.current of the cgroup with CgroupLimit
+	CgroupCurrent int64 `json:"cgroupCurrent,omitempty"`
+}
+
 // OCIRuntimeInfo describes the runtime (crun or runc) being
 // used with podman
 type OCIRuntimeInfo struct {
@@ -116,11 +464,29 @@ type OCIRuntimeInfo struct {
 	Package string `json:"package"`
 	Path    string `json:"path"`
 	Version string `json:"version"`
+	// SupportsJSON is true if the runtime is listed in runtime_supports_json
+	SupportsJSON bool `json:"supportsJSON"`
+	// SupportsKVM is true if the runtime is listed in runtime_supports_kvm
+	SupportsKVM bool `json:"supportsKVM"`
+	// SupportsNoCgroups is true if the runtime is listed in runtime_supports_nocgroup
+	SupportsNoCgroups bool `json:"supportsNoCgroups"`
+	// NoPivotRoot is true if containers are set up with MS_MOVE instead
+	// of pivot_root(2), as configured with no_pivot_root
+	NoPivotRoot bool `json:"noPivotRoot"`
 }
 
 // StoreInfo describes the container storage and its
 // attributes
 type StoreInfo struct {
+	// CompressionFormat is the format used to compress layers, e.g. when
+	// pushing images
+	CompressionFormat string `json:"compressionFormat"`
+	// CompressionLevel is the configured compression level, if any
+	CompressionLevel *int `json:"compressionLevel,omitempty"`
+	// AddCompression are the compression formats podman push adds
+	// instances of to manifest lists by default, as set by
+	// add_compression in containers.conf
+	AddCompression  []string               `json:"addCompression"`
 	ConfigFile      string                 `json:"configFile"`
 	ContainerStore  ContainerStore         `json:"containerStore"`
 	GraphDriverName string                 `json:"graphDriverName"`
@@ -129,19 +495,123 @@ type StoreInfo struct {
 	// GraphRootAllocated is how much space the graphroot has in bytes
 	GraphRootAllocated uint64 `json:"graphRootAllocated"`
 	// GraphRootUsed is how much of graphroot is used in bytes
-	GraphRootUsed   uint64            `json:"graphRootUsed"`
-	GraphStatus     map[string]string `json:"graphStatus"`
-	ImageCopyTmpDir string            `json:"imageCopyTmpDir"`
-	ImageStore      ImageStore        `json:"imageStore"`
-	RunRoot         string            `json:"runRoot"`
-	VolumePath      string            `json:"volumePath"`
-	TransientStore  bool              `json:"transientStore"`
+	GraphRootUsed uint64 `json:"graphRootUsed"`
+	// GraphRootLock is the path of the storage lock file
+	GraphRootLock string `json:"graphRootLock"`
+	// GraphRootLockHolder is the PID of another process currently holding
+	// the storage lock, if any
+	GraphRootLockHolder int               `json:"graphRootLockHolder,omitempty"`
+	GraphStatus         map[string]string `json:"graphStatus"`
+	ImageCopyTmpDir     string            `json:"imageCopyTmpDir"`
+	// ImageCopyTmpDirFree is how much space is available in ImageCopyTmpDir in bytes
+	ImageCopyTmpDirFree uint64 `json:"imageCopyTmpDirFree"`
+	// ImageCopyTmpDirSource is where ImageCopyTmpDir was resolved from
+	// ("env", "config" or "default")
+	ImageCopyTmpDirSource string     `json:"imageCopyTmpDirSource"`
+	ImageStore            ImageStore `json:"imageStore"`
+	// MountCount is the number of mounts in the mount namespace of Podman
+	MountCount int `json:"mountCount,omitempty"`
+	// MountLimit is the maximum number of mounts in a mount namespace,
+	// from /proc/sys/fs/mount-max
+	MountLimit int `json:"mountLimit,omitempty"`
+	// NativeOverlayDiff is true if the overlay driver computes layer
+	// diffs using the kernel overlay instead of comparing the files of
+	// the layers, which is slower.  Nil for other drivers.
+	NativeOverlayDiff *bool `json:"nativeOverlayDiff,omitempty"`
+	// OverlayMountProgram describes the FUSE program, usually
+	// fuse-overlayfs, mounting overlay layers.  Nil if the kernel overlay
+	// is used.
+	OverlayMountProgram *MountProgramInfo `json:"overlayMountProgram,omitempty"`
+	// OverlayMetacopy is the metacopy setting of the overlay driver
+	OverlayMetacopy *OverlayOption `json:"overlayMetacopy,omitempty"`
+	// OverlayRedirectDir is the redirect_dir setting of the overlay driver
+	OverlayRedirectDir *OverlayOption `json:"overlayRedirectDir,omitempty"`
+	// PullOptions are the pull_options from storage.conf, e.g.
+	// enable_partial_images for zstd:chunked layers
+	PullOptions    map[string]string `json:"pullOptions,omitempty"`
+	RunRoot        string            `json:"runRoot"`
+	VolumePath     string            `json:"volumePath"`
+	TransientStore bool              `json:"transientStore"`
+	// StorageWarnings describe layouts of the graph root and run root
+	// that are known to cause problems, e.g. a graph root on NFS
+	StorageWarnings []string `json:"storageWarnings,omitempty"`
+	// VolatileOverlaySupported is true if the kernel overlay supports
+	// the volatile mount option, which skips syncing the upper layer of
+	// containers.  Nil for other drivers and until the storage library
+	// checked it, when a container is first run with it.
+	VolatileOverlaySupported *bool `json:"volatileOverlaySupported,omitempty"`
+	// StorageMountCount is the number of mounts below the graph root and
+	// the run root, mostly the root file systems of containers
+	StorageMountCount int `json:"storageMountCount,omitempty"`
+	// AdditionalImageStores are the image stores used in addition to the
+	// graph root, e.g. shared stores configured with
+	// additionalimagestores in storage.conf
+	AdditionalImageStores []AdditionalImageStoreInfo `json:"additionalImageStores,omitempty"`
+	// TransientStoreInfo explains what the transient store means for
+	// the current setup.  Nil unless TransientStore is set.
+	TransientStoreInfo *TransientStoreInfo `json:"transientStoreInfo,omitempty"`
+	// LastWriterVersion is the version of Podman that last wrote to
+	// the store, empty if unknown
+	LastWriterVersion string `json:"lastWriterVersion,omitempty"`
+	// LastWriterVersionMismatch is true if LastWriterVersion differs from
+	// the version of this process, i.e. the store is shared between
+	// different versions of Podman
+	LastWriterVersionMismatch bool `json:"lastWriterVersionMismatch"`
+	// Locked is true if another process currently holds the store lock
+	Locked bool `json:"locked"`
+}
+
+// TransientStoreInfo describes where container state is kept with the
+// transient store
+type TransientStoreInfo struct {
+	// Path is the directory the container metadata is kept in instead of
+	// the graph root, i.e. the run root
+	Path string `json:"path"`
+	// DatabasePath is the path of the database of Podman
+	DatabasePath string `json:"databasePath"`
+	// Tmpfs is set if Path is on a tmpfs, so that containers and their
+	// state are lost on reboot
+	Tmpfs bool `json:"tmpfs"`
+	// Description explains the behavior in plain words
+	Description string `json:"description"`
+}
+
+// AdditionalImageStoreInfo describes an additional image store.  Podman
+// never modifies additional image stores, so their images cannot be
+// removed even if the store is writable.
+type AdditionalImageStoreInfo struct {
+	Path string `json:"path"`
+	// LayerStore is the path of the layer store of the store
+	LayerStore string `json:"layerStore"`
+	// ReadOnly is set if the store is not writable by the current user
+	ReadOnly bool `json:"readOnly"`
+	// Images is the number of images the store contributes
+	Images int `json:"images"`
+}
+
+// OverlayOption describes an overlay mount option as configured in the
+// storage options and as actually used by the storage library
+type OverlayOption struct {
+	// Configured is the value requested via mountopt, empty if not set
+	Configured string `json:"configured"`
+	// Effective is "on" or "off"
+	Effective string `json:"effective"`
+}
+
+// MountProgramInfo describes the program used to mount overlay layers
+type MountProgramInfo struct {
+	Executable string `json:"executable"`
+	Package    string `json:"package"`
+	Version    string `json:"version"`
 }
 
 // ImageStore describes the image store.  Right now only the number
 // of images present
 type ImageStore struct {
 	Number int `json:"number"`
+	// AdditionalImages is the number of the images that are in the
+	// additional image stores, which are included in Number
+	AdditionalImages int `json:"additionalImages"`
 }
 
 // ContainerStore describes the quantity of containers in the
@@ -159,10 +629,25 @@ type Plugins struct {
 	Log     []string `json:"log"`
 	// Authorization is provided for compatibility, will always be nil as Podman has no daemon
 	Authorization []string `json:"authorization"`
+	// VolumePlugins describes the volume plugins configured in containers.conf
+	VolumePlugins []VolumePluginInfo `json:"volumePlugins,omitempty"`
+}
+
+// VolumePluginInfo describes a volume plugin configured in containers.conf
+type VolumePluginInfo struct {
+	Name string `json:"name"`
+	// Path is the path of the plugin's socket
+	Path string `json:"path"`
+	// Timeout is the default timeout for requests to the plugin in
+	// seconds, 0 meaning no timeout.  Volumes may override it when created.
+	Timeout uint `json:"timeout"`
 }
 
 type CPUUsage struct {
 	UserPercent   float64 `json:"userPercent"`
 	SystemPercent float64 `json:"systemPercent"`
 	IdlePercent   float64 `json:"idlePercent"`
+	// PerCPU holds the utilization of each individual CPU. It is only
+	// populated when detailed information is requested.
+	PerCPU []CPUUsage `json:"perCPU,omitempty"`
 }
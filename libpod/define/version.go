@@ -2,7 +2,9 @@ package define
 
 import (
 	"runtime"
+	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/containers/podman/v5/version"
@@ -28,6 +30,8 @@ type Version struct {
 	Built      int64
 	OsArch     string
 	Os         string
+	// BuildTags are the Go build tags the binary was compiled with
+	BuildTags []string `json:",omitempty"`
 }
 
 // GetVersion returns a VersionOutput struct for API and podman
@@ -51,5 +55,21 @@ func GetVersion() (Version, error) {
 		Built:      buildTime,
 		OsArch:     runtime.GOOS + "/" + runtime.GOARCH,
 		Os:         runtime.GOOS,
+		BuildTags:  buildTags(),
 	}, nil
 }
+
+// buildTags returns the build tags recorded in the binary by the Go
+// toolchain.
+func buildTags() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "-tags" && setting.Value != "" {
+			return strings.Split(setting.Value, ",")
+		}
+	}
+	return nil
+}
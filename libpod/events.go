@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/containers/podman/v5/libpod/events"
 	"github.com/sirupsen/logrus"
@@ -158,6 +159,37 @@ func (r *Runtime) NewSystemEvent(status events.Status) {
 	}
 }
 
+// NewImagePullRecordEvent writes a pull-record event for the image id
+// pulled as name, with the error of the pull if pullErr is set.  It is
+// written in addition to the pull and pull-error events of libimage, so it
+// has a status of its own, and records the digest of the image, the
+// duration of the pull and whether the image was already present as
+// attributes.
+func (r *Runtime) NewImagePullRecordEvent(name, id, digest string, duration time.Duration, cacheHit bool, pullErr error) {
+	e := events.NewEvent(events.PullRecord)
+	e.ID = id
+	e.Name = name
+	e.Type = events.Image
+	result := "pulled"
+	if cacheHit {
+		result = "cached"
+	}
+	if pullErr != nil {
+		e.Error = pullErr.Error()
+		result = "failed"
+	}
+	e.Attributes = map[string]string{
+		"duration": duration.Round(time.Millisecond).String(),
+		"result":   result,
+	}
+	if digest != "" {
+		e.Attributes["digest"] = digest
+	}
+	if err := r.eventer.Write(e); err != nil {
+		logrus.Errorf("Unable to write image event: %q", err)
+	}
+}
+
 // newVolumeEvent creates a new event for a libpod volume
 func (v *Volume) newVolumeEvent(status events.Status) {
 	e := events.NewEvent(status)
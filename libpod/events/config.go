@@ -174,6 +174,9 @@ const (
 	Pull Status = "pull"
 	// PullError is an error pulling an image
 	PullError Status = "pull-error"
+	// PullRecord is the outcome of a pull recorded with its duration on
+	// request, in addition to the pull and pull-error events
+	PullRecord Status = "pull-record"
 	// Push ...
 	Push Status = "push"
 	// Refresh indicates that the system refreshed the state after a
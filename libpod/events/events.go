@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/containers/storage/pkg/stringid"
@@ -93,6 +95,14 @@ func (e *Event) ToHumanReadable(truncate bool) string {
 		if e.Error != "" {
 			humanFormat += " " + e.Error
 		}
+		if len(e.Attributes) > 0 {
+			attributes := make([]string, 0, len(e.Attributes))
+			for k, v := range e.Attributes {
+				attributes = append(attributes, k+"="+v)
+			}
+			sort.Strings(attributes)
+			humanFormat += " (" + strings.Join(attributes, ", ") + ")"
+		}
 	case System:
 		if e.Name != "" {
 			humanFormat = fmt.Sprintf("%s %s %s %s", e.Time, e.Type, e.Status, e.Name)
@@ -199,6 +209,8 @@ func StringToStatus(name string) (Status, error) {
 		return Pull, nil
 	case PullError.String():
 		return PullError, nil
+	case PullRecord.String():
+		return PullRecord, nil
 	case Push.String():
 		return Push, nil
 	case Refresh.String():
@@ -46,6 +46,13 @@ func (e EventJournalD) Write(ee Event) error {
 		if ee.Error != "" {
 			m["ERROR"] = ee.Error
 		}
+		if len(ee.Details.Attributes) > 0 {
+			b, err := json.Marshal(ee.Details.Attributes)
+			if err != nil {
+				return err
+			}
+			m["PODMAN_ATTRIBUTES"] = string(b)
+		}
 	case Container, Pod:
 		m["PODMAN_IMAGE"] = ee.Image
 		m["PODMAN_NAME"] = ee.Name
@@ -234,6 +241,11 @@ func newEventFromJournalEntry(entry *sdjournal.JournalEntry) (*Event, error) {
 		if val, ok := entry.Fields["ERROR"]; ok {
 			newEvent.Error = val
 		}
+		if val, ok := entry.Fields["PODMAN_ATTRIBUTES"]; ok && len(val) > 0 {
+			if err := json.Unmarshal([]byte(val), &newEvent.Attributes); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return &newEvent, nil
 }
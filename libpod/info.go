@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -17,15 +19,42 @@ import (
 	"github.com/containers/buildah"
 	"github.com/containers/buildah/pkg/parse"
 	"github.com/containers/buildah/pkg/util"
+	nettypes "github.com/containers/common/libnetwork/types"
+	"github.com/containers/common/pkg/hooks"
+	current "github.com/containers/common/pkg/hooks/1.0.0"
 	"github.com/containers/common/pkg/version"
 	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
 	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/libpod/events"
 	"github.com/containers/podman/v5/libpod/linkmode"
+	"github.com/containers/podman/v5/pkg/rootless"
+	podmanVersion "github.com/containers/podman/v5/version"
 	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/fileutils"
+	"github.com/containers/storage/pkg/homedir"
+	"github.com/containers/storage/pkg/lockfile"
 	"github.com/containers/storage/pkg/system"
+	"github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 )
 
+// tmpDirFromEnv records whether TMPDIR was set in the environment Podman was
+// started with, before it gets defaulted from containers.conf.
+var tmpDirFromEnv = func() bool {
+	_, found := os.LookupEnv("TMPDIR")
+	return found
+}()
+
+// perCPUSampleInterval is the time between the two samples used to compute
+// the per-CPU utilization.
+const perCPUSampleInterval = 100 * time.Millisecond
+
+// defaultMaxParallelDownloads is the number of layers containers/image pulls
+// in parallel if image_parallel_copies is not set.
+const defaultMaxParallelDownloads = 6
+
 // Info returns the store and host information
 func (r *Runtime) info() (*define.Info, error) {
 	info := define.Info{}
@@ -64,6 +93,30 @@ func (r *Runtime) info() (*define.Info, error) {
 	if len(regs) > 0 {
 		registries["search"] = regs
 	}
+	registryConfigPaths, err := registriesConfPaths(sys)
+	if err != nil {
+		return nil, fmt.Errorf("getting registries configuration files: %w", err)
+	}
+	info.RegistryConfigPaths = registryConfigPaths
+	shortNameMode, err := sysregistriesv2.GetShortNameMode(sys)
+	if err != nil {
+		return nil, fmt.Errorf("getting short-name mode: %w", err)
+	}
+	maxParallelDownloads := r.config.Engine.ImageParallelCopies
+	if maxParallelDownloads == 0 {
+		maxParallelDownloads = defaultMaxParallelDownloads
+	}
+	// libimage parses names without a transport as registry references,
+	// regardless of image_default_transport.
+	info.Locks = r.lockInfo()
+	info.Pull = &define.PullInfo{
+		ShortNameMode:               shortNameModeString(shortNameMode),
+		UnqualifiedSearchRegistries: regs,
+		MaxParallelDownloads:        maxParallelDownloads,
+		DefaultTransport:            define.DefaultTransport,
+		ImageDefaultTransport:       r.config.Engine.ImageDefaultTransport,
+		CompatAPIEnforceDockerHub:   r.config.Engine.CompatAPIEnforceDockerHub,
+	}
 	volumePlugins := make([]string, 0, len(r.config.Engine.VolumePlugins)+1)
 	// the local driver always exists
 	volumePlugins = append(volumePlugins, "local")
@@ -71,13 +124,140 @@ func (r *Runtime) info() (*define.Info, error) {
 		volumePlugins = append(volumePlugins, plugin)
 	}
 	info.Plugins.Volume = volumePlugins
+	for plugin, path := range r.config.Engine.VolumePlugins {
+		info.Plugins.VolumePlugins = append(info.Plugins.VolumePlugins, define.VolumePluginInfo{
+			Name:    plugin,
+			Path:    path,
+			Timeout: r.config.Engine.VolumePluginTimeout,
+		})
+	}
+	sort.Slice(info.Plugins.VolumePlugins, func(i, j int) bool {
+		return info.Plugins.VolumePlugins[i].Name < info.Plugins.VolumePlugins[j].Name
+	})
 	info.Plugins.Network = r.network.Drivers()
 	info.Plugins.Log = logDrivers
 
 	info.Registries = registries
+	info.ContainerDefaults = r.containerDefaultsInfo()
+	info.ContainerDNS = &define.ContainerDNSInfo{
+		Servers:  r.config.Containers.DNSServers.Get(),
+		Searches: r.config.Containers.DNSSearches.Get(),
+		Options:  r.config.Containers.DNSOptions.Get(),
+	}
+	info.SecurityDefaults = securityDefaultsInfo(r.config.Containers.DefaultCapabilities.Get(), r.config.Containers.DefaultUlimits.Get())
 	return &info, nil
 }
 
+// userRegistriesConfPath is the per-user registries.conf, relative to the
+// home directory.  If it exists, the system drop-in directory is not read.
+var userRegistriesConfPath = filepath.FromSlash(".config/containers/registries.conf")
+
+// registriesConfDirs returns the registries.conf.d directories
+// sysregistriesv2 reads for sys, in load order.
+func registriesConfDirs(sys *types.SystemContext) []string {
+	//nolint:staticcheck // The deprecated API is the only one returning the path.
+	dir := sysregistriesv2.ConfigDirPath(sys)
+	// ConfigDirPath only returns the last directory.  The system one is
+	// read before the user one, unless a directory was chosen explicitly
+	// or the per-user registries.conf is used.
+	if sys != nil && sys.SystemRegistriesConfDirPath != "" {
+		return []string{dir}
+	}
+	if sys == nil || sys.SystemRegistriesConfPath == "" {
+		//nolint:staticcheck // The deprecated API is the only one returning the path.
+		if sysregistriesv2.ConfigPath(sys) == filepath.Join(homedir.Get(), userRegistriesConfPath) {
+			return []string{dir}
+		}
+	}
+	systemDir := systemRegistriesConfDirPath
+	if sys != nil && sys.RootForImplicitAbsolutePaths != "" {
+		systemDir = filepath.Join(sys.RootForImplicitAbsolutePaths, systemDir)
+	}
+	return []string{systemDir, dir}
+}
+
+// registriesConfPaths returns the existing registries.conf files used for
+// sys in the order they are loaded: the main file followed by the *.conf
+// drop-in files of the system and the user directory, sorted by name.
+func registriesConfPaths(sys *types.SystemContext) ([]string, error) {
+	paths := []string{}
+	//nolint:staticcheck // The deprecated API is the only one returning the path.
+	if conf := sysregistriesv2.ConfigPath(sys); fileutils.Exists(conf) == nil {
+		paths = append(paths, conf)
+	}
+	for _, dir := range registriesConfDirs(sys) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".conf") {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return paths, nil
+}
+
+func shortNameModeString(mode types.ShortNameMode) string {
+	switch mode {
+	case types.ShortNameModeDisabled:
+		return "disabled"
+	case types.ShortNameModePermissive:
+		return "permissive"
+	case types.ShortNameModeEnforcing:
+		return "enforcing"
+	default:
+		return "invalid"
+	}
+}
+
+// containerDefaultsInfo reports the containers.conf defaults applied to
+// new containers.
+func (r *Runtime) containerDefaultsInfo() *define.ContainerDefaultsInfo {
+	sysctls := map[string]string{}
+	for _, sysctl := range r.config.Sysctls() {
+		if key, val, ok := strings.Cut(sysctl, "="); ok {
+			sysctls[key] = val
+		}
+	}
+	return &define.ContainerDefaultsInfo{
+		CgroupConf:      r.config.Containers.CgroupConf.Get(),
+		Capabilities:    r.config.Containers.DefaultCapabilities.Get(),
+		DefaultSysctls:  sysctls,
+		ImageVolumeMode: r.config.Engine.ImageVolumeMode,
+		NetworkMode:     r.defaultNetworkMode(),
+		PidsLimit:       r.config.Containers.PidsLimit,
+		TZ:              r.config.Containers.TZ,
+		Ulimits:         r.config.Containers.DefaultUlimits.Get(),
+	}
+}
+
+// defaultNetworkMode resolves the netns setting of containers.conf to the
+// network mode of new containers, like container creation does: the
+// private default is the default rootless network command for rootless
+// users and bridge for root, and named networks are bridge networks.
+func (r *Runtime) defaultNetworkMode() string {
+	mode, _, _ := strings.Cut(r.config.Containers.NetNS, ":")
+	switch mode {
+	case "", "default", "private":
+		if !rootless.IsRootless() {
+			return "bridge"
+		}
+		if r.config.Network.DefaultRootlessNetworkCmd == "" {
+			return "slirp4netns"
+		}
+		return r.config.Network.DefaultRootlessNetworkCmd
+	case "bridge", "container", "host", "none", "ns", "pasta", "pod", "slirp4netns":
+		return mode
+	default:
+		return "bridge"
+	}
+}
+
 // top-level "host" info
 func (r *Runtime) hostInfo() (*define.HostInfo, error) {
 	// let's say OS, arch, number of cpus, amount of memory, maybe os distribution/version, hostname, kernel version, uptime
@@ -129,6 +309,7 @@ func (r *Runtime) hostInfo() (*define.HostInfo, error) {
 		RootlessNetworkCmd: r.config.Network.DefaultRootlessNetworkCmd,
 		SwapFree:           mi.SwapFree,
 		SwapTotal:          mi.SwapTotal,
+		Timezone:           hostTimezone(),
 	}
 	platform := parse.DefaultPlatform()
 	pArr := strings.Split(platform, "/")
@@ -138,6 +319,9 @@ func (r *Runtime) hostInfo() (*define.HostInfo, error) {
 	if err := r.setPlatformHostInfo(&info); err != nil {
 		return nil, err
 	}
+	r.setEventLogInfo(&info)
+	info.Hooks = r.hooksInfo()
+	info.NetworkPlugins = r.networkPluginsInfo(info.NetworkBackendInfo.Backend)
 
 	conmonInfo, ociruntimeInfo, err := r.defaultOCIRuntime.RuntimeInfo()
 	if err != nil {
@@ -177,6 +361,40 @@ func (r *Runtime) hostInfo() (*define.HostInfo, error) {
 	return &info, nil
 }
 
+// hostTimezone returns the name of the timezone of the host.  If neither
+// $TZ nor the /etc/localtime symlink name one, the abbreviation of the
+// current zone is returned.
+func hostTimezone() string {
+	if tz, ok := os.LookupEnv("TZ"); ok {
+		if tz = strings.TrimPrefix(tz, ":"); tz != "" {
+			return tz
+		}
+		return "UTC"
+	}
+	if path, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if tz := timezoneFromPath(path); tz != "" {
+			return tz
+		}
+	}
+	zone, _ := time.Now().Zone()
+	return zone
+}
+
+// timezoneFromPath returns the timezone name of a zoneinfo file path, e.g.
+// Europe/Berlin for /usr/share/zoneinfo/Europe/Berlin, or an empty string if
+// the path is not in a zoneinfo directory.
+func timezoneFromPath(path string) string {
+	_, tz, ok := strings.Cut(path, "/zoneinfo/")
+	if !ok {
+		return ""
+	}
+	// Some distributions have extra trees for leap second aware zones
+	for _, prefix := range []string{"posix/", "right/"} {
+		tz = strings.TrimPrefix(tz, prefix)
+	}
+	return tz
+}
+
 func (r *Runtime) getContainerStoreInfo() (define.ContainerStore, error) {
 	var paused, running, stopped int
 	cs := define.ContainerStore{}
@@ -226,6 +444,12 @@ func (r *Runtime) storeInfo() (*define.StoreInfo, error) {
 		return nil, err
 	}
 	imageInfo := define.ImageStore{Number: len(images)}
+	for _, image := range images {
+		// Only images of additional image stores are read-only.
+		if image.ReadOnly {
+			imageInfo.AdditionalImages++
+		}
+	}
 
 	var grStats syscall.Statfs_t
 	if err := syscall.Statfs(r.store.GraphRoot(), &grStats); err != nil {
@@ -239,12 +463,21 @@ func (r *Runtime) storeInfo() (*define.StoreInfo, error) {
 		GraphRoot:          r.store.GraphRoot(),
 		GraphRootAllocated: allocated,
 		GraphRootUsed:      allocated - (uint64(grStats.Bsize) * grStats.Bfree),
+		GraphRootLock:      filepath.Join(r.store.GraphRoot(), "storage.lock"),
 		RunRoot:            r.store.RunRoot(),
 		GraphDriverName:    r.store.GraphDriverName(),
 		GraphOptions:       nil,
 		VolumePath:         r.config.Engine.VolumePath,
 		ConfigFile:         configFile,
 		TransientStore:     r.store.TransientStore(),
+		CompressionFormat:  r.config.Engine.CompressionFormat,
+		CompressionLevel:   r.config.Engine.CompressionLevel,
+		AddCompression:     r.config.Engine.AddCompression.Get(),
+		PullOptions:        r.store.PullOptions(),
+	}
+	if info.CompressionFormat == "" {
+		// c/image defaults to gzip
+		info.CompressionFormat = "gzip"
 	}
 
 	graphOptions := map[string]interface{}{}
@@ -275,9 +508,293 @@ func (r *Runtime) storeInfo() (*define.StoreInfo, error) {
 		status[pair[0]] = pair[1]
 	}
 	info.GraphStatus = status
+	if info.AdditionalImageStores, err = r.additionalImageStoresInfo(images); err != nil {
+		return nil, err
+	}
+	if info.TransientStore {
+		info.TransientStoreInfo = r.transientStoreInfo()
+	}
+	r.setPlatformStoreInfo(&info)
+	info.StorageWarnings = storageWarnings(info.GraphRoot, info.RunRoot)
+	info.LastWriterVersion = r.storeWriterVersion()
+	info.Locked = storeLocked(info.GraphRoot)
+	if info.LastWriterVersion != "" && info.LastWriterVersion != podmanVersion.Version.String() {
+		info.LastWriterVersionMismatch = true
+		if info.Locked {
+			info.StorageWarnings = append(info.StorageWarnings, fmt.Sprintf("the store is currently locked by another process and was last written by Podman %s, this is Podman %s; sharing a store between different versions of Podman can corrupt it", info.LastWriterVersion, podmanVersion.Version))
+		} else {
+			info.StorageWarnings = append(info.StorageWarnings, fmt.Sprintf("the store was last written by Podman %s, this is Podman %s; sharing a store between different versions of Podman can corrupt it", info.LastWriterVersion, podmanVersion.Version))
+		}
+	}
+
+	switch {
+	case tmpDirFromEnv:
+		info.ImageCopyTmpDirSource = "env"
+	case r.config.Engine.ImageCopyTmpDir != "/var/tmp":
+		info.ImageCopyTmpDirSource = "config"
+	default:
+		info.ImageCopyTmpDirSource = "default"
+	}
+	if info.ImageCopyTmpDir != "" {
+		var tmpStats syscall.Statfs_t
+		if err := syscall.Statfs(info.ImageCopyTmpDir, &tmpStats); err != nil {
+			logrus.Debugf("Unable to collect free space of image copy tmp dir %q: %v", info.ImageCopyTmpDir, err)
+		} else {
+			info.ImageCopyTmpDirFree = uint64(tmpStats.Bsize) * uint64(tmpStats.Bavail)
+		}
+	}
 	return &info, nil
 }
 
+// eventLogWarnRatio is the share of the maximum size of the event log file
+// above which info warns that it is about to be rotated.
+const eventLogWarnRatio = 0.9
+
+// setEventLogInfo reports the event log file and its size when using the
+// file event logger.
+func (r *Runtime) setEventLogInfo(info *define.HostInfo) {
+	if info.EventLogger != events.LogFile.String() {
+		return
+	}
+	info.EventLogFile = r.config.Engine.EventsLogFilePath
+	info.EventLogMaxSize = r.config.Engine.EventsLogMaxSize()
+	st, err := os.Stat(info.EventLogFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("Unable to get the size of the event log: %v", err)
+		}
+		return
+	}
+	info.EventLogSize = st.Size()
+	if info.EventLogMaxSize > 0 && float64(info.EventLogSize) >= eventLogWarnRatio*float64(info.EventLogMaxSize) {
+		logrus.Warnf("The event log %s is at %s of its maximum size %s, older events are lost when it is rotated", info.EventLogFile, units.BytesSize(float64(info.EventLogSize)), units.BytesSize(float64(info.EventLogMaxSize)))
+	}
+}
+
+// networkPluginsInfo describes the plugin directories of the network
+// backend and the plugin binaries found in them.
+func (r *Runtime) networkPluginsInfo(backend nettypes.NetworkBackend) *define.NetworkPluginsInfo {
+	var dirs []string
+	switch backend {
+	case nettypes.CNI:
+		dirs = r.config.Network.CNIPluginDirs.Get()
+	case nettypes.Netavark:
+		dirs = r.config.Network.NetavarkPluginDirs.Get()
+	default:
+		return nil
+	}
+	return &define.NetworkPluginsInfo{Dirs: dirs, Plugins: findPlugins(dirs)}
+}
+
+// findPlugins returns the paths of the executables in dirs, skipping those
+// shadowed by an executable of the same name in an earlier directory.
+// Missing directories are ignored.
+func findPlugins(dirs []string) []string {
+	plugins := []string{}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logrus.Debugf("Unable to list network plugins in %s: %v", dir, err)
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if seen[entry.Name()] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			// Follow symlinks, plugins are often linked into place.
+			st, err := os.Stat(path)
+			if err != nil || !st.Mode().IsRegular() || st.Mode().Perm()&0o111 == 0 {
+				continue
+			}
+			seen[entry.Name()] = true
+			plugins = append(plugins, path)
+		}
+	}
+	return plugins
+}
+
+// lockInfo describes the lock backend.
+func (r *Runtime) lockInfo() *define.LockInfo {
+	info := &define.LockInfo{Type: r.config.Engine.LockType}
+	if info.Type == "file" {
+		info.Path = fileLockPath(r.config.Engine.TmpDir)
+	} else {
+		info.Type = "shm"
+		info.Path = filepath.Join("/dev/shm", shmLockPath())
+		info.NumLocks = r.config.Engine.NumLocks
+	}
+	return info
+}
+
+// storeWriterVersion returns the version of Podman that last wrote to the
+// store, empty if unknown.
+func (r *Runtime) storeWriterVersion() string {
+	data, err := os.ReadFile(filepath.Join(r.config.Engine.StaticDir, storeVersionFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("Reading the Podman version of the store: %v", err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// storeLocked returns true if another process currently holds the lock of
+// the store in graphRoot.  The lock is only probed, never waited for.
+func storeLocked(graphRoot string) bool {
+	lock, err := lockfile.GetLockFile(filepath.Join(graphRoot, "storage.lock"))
+	if err != nil {
+		logrus.Debugf("Opening the store lock: %v", err)
+		return false
+	}
+	if err := lock.TryLock(); err != nil {
+		return true
+	}
+	lock.Unlock()
+	return false
+}
+
+// storageWarnings returns warnings about layouts of the graph root and the
+// run root that are known to cause problems.
+func storageWarnings(graphRoot, runRoot string) []string {
+	var warnings []string
+	if fs, err := networkFilesystem(graphRoot); err != nil {
+		logrus.Debugf("Unable to determine the file system of %s: %v", graphRoot, err)
+	} else if fs != "" {
+		warnings = append(warnings, fmt.Sprintf("graphRoot %s is on a network file system (%s), which does not support the locking and extended attributes container storage relies on", graphRoot, fs))
+	}
+	tmpfs, err := isTmpfs(graphRoot)
+	if err != nil {
+		logrus.Debugf("Unable to determine the file system of %s: %v", graphRoot, err)
+	} else if tmpfs {
+		warnings = append(warnings, fmt.Sprintf("graphRoot %s is on tmpfs, images and containers are limited by memory and lost on reboot", graphRoot))
+	}
+	var grStat, rrStat unix.Stat_t
+	if err := unix.Stat(graphRoot, &grStat); err != nil {
+		logrus.Debugf("Unable to stat %s: %v", graphRoot, err)
+	} else if err := unix.Stat(runRoot, &rrStat); err != nil {
+		logrus.Debugf("Unable to stat %s: %v", runRoot, err)
+	} else if grStat.Dev == rrStat.Dev {
+		warnings = append(warnings, fmt.Sprintf("runRoot %s and graphRoot %s share a file system, runtime state and images compete for space on it", runRoot, graphRoot))
+	}
+	return warnings
+}
+
+// hooksInfo describes the hooks directories and the effective hooks in
+// them, resolved the way containers are set up.
+func (r *Runtime) hooksInfo() *define.HooksInfo {
+	dirs := r.config.Engine.HooksDir.Get()
+	implicit := len(dirs) == 0
+	if implicit {
+		if rootless.IsRootless() {
+			return nil
+		}
+		dirs = []string{hooks.DefaultDir, hooks.OverrideDir}
+	}
+	return &define.HooksInfo{Dirs: dirs, Implicit: implicit, Hooks: effectiveHooks(dirs, implicit)}
+}
+
+// effectiveHooks returns the hooks in dirs.  Unless implicit is set, a hook
+// overrides hooks with the same file name in earlier directories.
+func effectiveHooks(dirs []string, implicit bool) []define.HookInfo {
+	effective := []define.HookInfo{}
+	index := make(map[string]int)
+	for _, dir := range dirs {
+		found := make(map[string]*current.Hook)
+		if err := hooks.ReadDir(dir, []string{"precreate", "poststop"}, found); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("Reading hooks from %s: %v", dir, err)
+		}
+		names := make([]string, 0, len(found))
+		for name := range found {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			hook := define.HookInfo{Name: name, Path: filepath.Join(dir, name), Stages: found[name].Stages}
+			if i, ok := index[name]; ok && !implicit {
+				hook.Overrides = append(effective[i].Overrides, effective[i].Path)
+				effective[i] = hook
+				continue
+			}
+			index[name] = len(effective)
+			effective = append(effective, hook)
+		}
+	}
+	// The hooks manager applies hooks sorted by their name.
+	sort.SliceStable(effective, func(i, j int) bool {
+		return strings.ToLower(effective[i].Name) < strings.ToLower(effective[j].Name)
+	})
+	return effective
+}
+
+// transientStoreInfo describes where the transient store keeps container
+// state and whether it survives a reboot.
+func (r *Runtime) transientStoreInfo() *define.TransientStoreInfo {
+	info := &define.TransientStoreInfo{Path: r.store.RunRoot()}
+	switch state := r.state.(type) {
+	case *SQLiteState:
+		info.DatabasePath = filepath.Join(r.store.RunRoot(), "db.sql")
+	case *BoltState:
+		info.DatabasePath = state.dbPath
+	}
+	tmpfs, err := isTmpfs(info.Path)
+	if err != nil {
+		logrus.Debugf("Unable to determine the file system of %s: %v", info.Path, err)
+	}
+	info.Tmpfs = tmpfs
+	if tmpfs {
+		info.Description = fmt.Sprintf("Containers, pods and the database are stored on tmpfs in %s and are lost on reboot. Images are not affected.", info.Path)
+	} else {
+		info.Description = fmt.Sprintf("Containers, pods and the database are stored in %s. It is not on tmpfs, so they survive a reboot unless it is cleaned up at boot. Images are not affected.", info.Path)
+	}
+	return info
+}
+
+// additionalImageStoresInfo describes the additional image stores of the
+// graph driver and how many images each of them contributes.
+func (r *Runtime) additionalImageStoresInfo(images []storage.Image) ([]define.AdditionalImageStoreInfo, error) {
+	driver, err := r.store.GraphDriver()
+	if err != nil {
+		return nil, fmt.Errorf("getting graph driver: %w", err)
+	}
+	paths := driver.AdditionalImageStores()
+	counts := additionalStoreImages(paths, driver.String(), images)
+	var stores []define.AdditionalImageStoreInfo
+	for i, store := range paths {
+		stores = append(stores, define.AdditionalImageStoreInfo{
+			Path:       store,
+			LayerStore: filepath.Join(store, driver.String()+"-layers"),
+			ReadOnly:   unix.Access(store, unix.W_OK) != nil,
+			Images:     counts[i],
+		})
+	}
+	return stores, nil
+}
+
+// additionalStoreImages returns the number of the read-only images, which
+// come from the additional image stores, in each of the stores.  The storage
+// library reads an image from the first store listing it, so an image is
+// only counted for that store.
+func additionalStoreImages(stores []string, driverName string, images []storage.Image) []int {
+	counts := make([]int, len(stores))
+	for _, image := range images {
+		if !image.ReadOnly {
+			continue
+		}
+		for i, store := range stores {
+			if err := fileutils.Exists(filepath.Join(store, driverName+"-images", image.ID)); err == nil {
+				counts[i]++
+				break
+			}
+		}
+	}
+	return counts
+}
+
 // GetHostDistributionInfo returns a map containing the host's distribution and version
 func (r *Runtime) GetHostDistributionInfo() define.DistributionInfo {
 	// Populate values in case we cannot find the values
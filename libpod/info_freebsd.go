@@ -4,16 +4,64 @@ package libpod
 
 import (
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/containers/podman/v5/libpod/define"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 )
 
 func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
+	var rlimit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rlimit); err != nil {
+		logrus.Debugf("Reading the open file limit: %v", err)
+	} else {
+		// The limits are signed on FreeBSD, with RLIM_INFINITY being
+		// the largest value.
+		info.RLimitNofileSoft, info.RLimitNofileHard = uint64(rlimit.Cur), uint64(rlimit.Max)
+	}
+	return nil
+}
+
+// securityDefaultsInfo returns nil, as there are no capabilities to
+// compare.
+func securityDefaultsInfo(caps, ulimits []string) *define.SecurityDefaultsInfo {
 	return nil
 }
 
+// CRIUInfo describes the CRIU installation used to checkpoint and restore
+// containers on this host.  Nil, as checkpoint/restore is not supported.
+func (r *Runtime) CRIUInfo() *define.CRIUInfo {
+	return nil
+}
+
+func (r *Runtime) setPlatformStoreInfo(info *define.StoreInfo) {
+}
+
+// isTmpfs returns whether path is on a tmpfs.
+func isTmpfs(path string) (bool, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false, err
+	}
+	return unix.ByteSliceToString(st.Fstypename[:]) == "tmpfs", nil
+}
+
+// networkFilesystem returns the name of the network file system path is
+// on, or an empty string if it is not on a network file system.
+func networkFilesystem(path string) (string, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	switch fs := unix.ByteSliceToString(st.Fstypename[:]); fs {
+	case "nfs", "smbfs":
+		return fs, nil
+	}
+	return "", nil
+}
+
 func timeToPercent(time uint64, total uint64) float64 {
 	return 100.0 * float64(time) / float64(total)
 }
@@ -40,3 +88,54 @@ func getCPUUtilization() (*define.CPUUsage, error) {
 		IdlePercent:   timeToPercent(times[unix.CP_IDLE], total),
 	}, nil
 }
+
+// readPerCPUTimes returns the CPU state times of every CPU.
+func readPerCPUTimes() ([][unix.CPUSTATES]uint64, error) {
+	buf, err := unix.SysctlRaw("kern.cp_times")
+	if err != nil {
+		return nil, fmt.Errorf("reading sysctl kern.cp_times: %w", err)
+	}
+	times := make([][unix.CPUSTATES]uint64, len(buf)/(8*unix.CPUSTATES))
+	for cpu := range times {
+		for i := 0; i < unix.CPUSTATES; i++ {
+			times[cpu][i] = *(*uint64)(unsafe.Pointer(&buf[8*(cpu*unix.CPUSTATES+i)]))
+		}
+	}
+	return times, nil
+}
+
+// getPerCPUUtilization returns the utilization of each CPU, computed from
+// two samples of kern.cp_times taken perCPUSampleInterval apart.
+func getPerCPUUtilization() ([]define.CPUUsage, error) {
+	before, err := readPerCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(perCPUSampleInterval)
+	after, err := readPerCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]define.CPUUsage, 0, len(after))
+	for cpu := range after {
+		var total uint64
+		var delta [unix.CPUSTATES]uint64
+		for i := 0; i < unix.CPUSTATES; i++ {
+			delta[i] = after[cpu][i] - before[cpu][i]
+			total += delta[i]
+		}
+		if total == 0 {
+			usage = append(usage, define.CPUUsage{IdlePercent: 100})
+			continue
+		}
+		usage = append(usage, define.CPUUsage{
+			UserPercent:   timeToPercent(delta[unix.CP_USER], total),
+			SystemPercent: timeToPercent(delta[unix.CP_SYS], total),
+			IdlePercent:   timeToPercent(delta[unix.CP_IDLE], total),
+		})
+	}
+	return usage, nil
+}
+
+// systemRegistriesConfDirPath is the system registries.conf.d directory.
+const systemRegistriesConfDirPath = "/usr/local/etc/containers/registries.conf.d"
@@ -4,24 +4,38 @@ package libpod
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/containers/common/libnetwork/pasta"
 	"github.com/containers/common/libnetwork/slirp4netns"
 	"github.com/containers/common/pkg/apparmor"
+	"github.com/containers/common/pkg/capabilities"
 	"github.com/containers/common/pkg/cgroups"
+	"github.com/containers/common/pkg/rootlessport"
 	"github.com/containers/common/pkg/seccomp"
+	"github.com/containers/common/pkg/subscriptions"
 	"github.com/containers/common/pkg/version"
 	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/criu"
 	"github.com/containers/podman/v5/pkg/rootless"
 	"github.com/containers/podman/v5/pkg/util"
+	"github.com/containers/storage/pkg/fileutils"
+	"github.com/containers/storage/pkg/idtools"
 	"github.com/containers/storage/pkg/unshare"
+	"github.com/docker/go-units"
 	"github.com/opencontainers/selinux/go-selinux"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 )
 
 func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
@@ -45,13 +59,23 @@ func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
 	info.CgroupManager = r.config.Engine.CgroupManager
 	info.CgroupControllers = availableControllers
 	info.IDMappings = define.IDMappings{}
+	capsList, capsDropped := defaultCapabilities(r.config.Containers.DefaultCapabilities.Get())
 	info.Security = define.SecurityInfo{
-		AppArmorEnabled:     apparmor.IsEnabled(),
-		DefaultCapabilities: strings.Join(r.config.Containers.DefaultCapabilities.Get(), ","),
-		Rootless:            rootless.IsRootless(),
-		SECCOMPEnabled:      seccomp.IsEnabled(),
-		SECCOMPProfilePath:  seccompProfilePath,
-		SELinuxEnabled:      selinux.GetEnabled(),
+		AppArmorEnabled:         apparmor.IsEnabled(),
+		DefaultCapabilities:     strings.Join(r.config.Containers.DefaultCapabilities.Get(), ","),
+		DefaultCapabilitiesList: capsList,
+		DroppedCapabilities:     capsDropped,
+		FIPSEnabled:             fipsEnabled(),
+		KeyringAvailable:        keyringAvailable(),
+		Rootless:                rootless.IsRootless(),
+		SECCOMPEnabled:          seccomp.IsEnabled(),
+		SECCOMPProfilePath:      seccompProfilePath,
+		SELinuxEnabled:          selinux.GetEnabled(),
+		UserNS:                  userNSInfo(),
+	}
+	info.Security.DefaultMountsFile = defaultMountsFile(r.config.Containers.DefaultMountsFile, rootless.IsRootless())
+	if info.Security.DefaultMountsFile != "" {
+		info.Security.DefaultMounts = countDefaultMounts(info.Security.DefaultMountsFile)
 	}
 	info.Slirp4NetNS = define.SlirpInfo{}
 
@@ -60,6 +84,20 @@ func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
 		cgroupVersion = "v2"
 	}
 	info.CgroupsVersion = cgroupVersion
+	info.CgroupHybrid = !unified && cgroupHybrid("/sys/fs/cgroup/unified")
+	if info.CgroupHybrid {
+		procCgroups, err := os.ReadFile("/proc/cgroups")
+		if err != nil {
+			logrus.Debugf("Reading cgroup controllers: %v", err)
+		}
+		unifiedControllers, err := os.ReadFile("/sys/fs/cgroup/unified/cgroup.controllers")
+		if err != nil {
+			logrus.Debugf("Reading cgroup v2 controllers: %v", err)
+		}
+		v1, v2 := hybridControllers(string(procCgroups), string(unifiedControllers))
+		logrus.Warnf("Detected a hybrid cgroup v1/v2 setup, resource limits may not work as expected: controllers on cgroup v1: %s, on cgroup v2: %s",
+			controllerList(v1), controllerList(v2))
+	}
 
 	slirp4netnsPath := r.config.Engine.NetworkCmdPath
 	if slirp4netnsPath == "" {
@@ -74,6 +112,7 @@ func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
 			Executable: slirp4netnsPath,
 			Package:    version.Package(slirp4netnsPath),
 			Version:    ver,
+			Sandboxed:  r.slirp4netnsSandboxed(slirp4netnsPath),
 		}
 		info.Slirp4NetNS = program
 	}
@@ -88,10 +127,29 @@ func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
 			Executable: pastaPath,
 			Package:    version.Package(pastaPath),
 			Version:    ver,
+			// pasta sandboxes itself unconditionally, there is no
+			// option to turn it off.
+			Sandboxed: true,
 		}
 		info.Pasta = program
 	}
 
+	info.RootlessNetworkCmdSource, info.RootlessNetworkCmdReason = rootlessNetworkCmdSelection(r.config.Network.DefaultRootlessNetworkCmd, info.Pasta.Executable, info.Slirp4NetNS.Executable)
+	info.HelperBinaries = r.helperBinariesInfo()
+	info.IDMappedMountsSupported = idMappedMountsSupported()
+	info.SystemdUnit = systemdUnitInfo()
+	info.RootlessPortForwarder = r.rootlessPortForwarder()
+	info.CRIU = criuInfo()
+	info.ClockSynchronized = clockSynchronized()
+	info.Pids = pidsInfo(rootless.IsRootless() && unified)
+
+	var rlimit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rlimit); err != nil {
+		logrus.Debugf("Reading the open file limit: %v", err)
+	} else {
+		info.RLimitNofileSoft, info.RLimitNofileHard = rlimit.Cur, rlimit.Max
+	}
+
 	if rootless.IsRootless() {
 		uidmappings, gidmappings, err := unshare.GetHostIDMappings("")
 		if err != nil {
@@ -102,11 +160,719 @@ func (r *Runtime) setPlatformHostInfo(info *define.HostInfo) error {
 			UIDMap: util.RuntimeSpecToIDtools(uidmappings),
 		}
 		info.IDMappings = idmappings
+		info.RootlessIDMappingMode = idMappingMode(idmappings.UIDMap)
 	}
 
 	return nil
 }
 
+// clockSynchronized reports whether the kernel considers the system clock
+// synchronized, as shown by timedatectl.
+func clockSynchronized() *bool {
+	var tx unix.Timex
+	if _, err := unix.Adjtimex(&tx); err != nil {
+		logrus.Debugf("Reading clock status: %v", err)
+		return nil
+	}
+	synced := tx.Status&unix.STA_UNSYNC == 0
+	return &synced
+}
+
+// pidsWarnRatio is the share of the PID limit in use above which info
+// warns about running out of PIDs.
+const pidsWarnRatio = 0.9
+
+// pidsInfo describes the PIDs in use and kernel.pid_max, and with
+// withCgroup the pids limit of the cgroup of the process.  It warns if
+// either limit is close to being reached.
+func pidsInfo(withCgroup bool) *define.PidsInfo {
+	info := &define.PidsInfo{}
+	val, err := os.ReadFile("/proc/sys/kernel/pid_max")
+	if err != nil {
+		logrus.Debugf("Unable to read the PID limit: %v", err)
+		return nil
+	}
+	if info.PidMax, err = strconv.ParseInt(strings.TrimSpace(string(val)), 10, 64); err != nil {
+		logrus.Debugf("Unable to parse the PID limit %q: %v", val, err)
+		return nil
+	}
+	loadavg, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		logrus.Debugf("Unable to read the number of PIDs in use: %v", err)
+		return nil
+	}
+	if info.InUse, err = pidsInUse(string(loadavg)); err != nil {
+		logrus.Debugf("Unable to parse the number of PIDs in use: %v", err)
+		return nil
+	}
+	if float64(info.InUse) >= pidsWarnRatio*float64(info.PidMax) {
+		logrus.Warnf("%d of at most %d PIDs (kernel.pid_max) are in use, starting containers may fail soon", info.InUse, info.PidMax)
+	}
+
+	if !withCgroup {
+		return info
+	}
+	cgroup, err := cgroups.GetOwnCgroup()
+	if err != nil {
+		logrus.Debugf("Reading own cgroup: %v", err)
+		return info
+	}
+	info.CgroupLimit, info.CgroupCurrent = cgroupPidsLimit("/sys/fs/cgroup", cgroup)
+	if info.CgroupLimit > 0 && float64(info.CgroupCurrent) >= pidsWarnRatio*float64(info.CgroupLimit) {
+		logrus.Warnf("%d of at most %d PIDs of the session cgroup are in use, starting containers may fail soon", info.CgroupCurrent, info.CgroupLimit)
+	}
+	return info
+}
+
+// pidsInUse parses the number of scheduling entities, i.e. threads, from
+// the contents of /proc/loadavg, e.g. "0.11 0.14 0.10 2/611 4242".
+func pidsInUse(loadavg string) (int64, error) {
+	fields := strings.Fields(loadavg)
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected format %q", loadavg)
+	}
+	_, total, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return 0, fmt.Errorf("unexpected format %q", loadavg)
+	}
+	return strconv.ParseInt(total, 10, 64)
+}
+
+// cgroupPidsLimit returns the lowest pids.max of cgroup and its ancestors
+// in the cgroup v2 hierarchy mounted at root, and pids.current of the
+// cgroup it is set on.  The limit is 0 if no cgroup has one.
+func cgroupPidsLimit(root, cgroup string) (limit, current int64) {
+	for dir := cgroup; ; dir = filepath.Dir(dir) {
+		val, err := os.ReadFile(filepath.Join(root, dir, "pids.max"))
+		if err == nil {
+			n, err := strconv.ParseInt(strings.TrimSpace(string(val)), 10, 64)
+			// The value is "max" if there is no limit.
+			if err == nil && (limit == 0 || n < limit) {
+				limit = n
+				current = 0
+				if val, err := os.ReadFile(filepath.Join(root, dir, "pids.current")); err == nil {
+					current, _ = strconv.ParseInt(strings.TrimSpace(string(val)), 10, 64)
+				}
+			}
+		}
+		if dir == "/" || dir == "." {
+			return limit, current
+		}
+	}
+}
+
+// cgroupHybrid returns whether a cgroup v2 hierarchy is mounted at path,
+// which systemd does in hybrid mode next to the cgroup v1 hierarchies.
+func cgroupHybrid(path string) bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false
+	}
+	return st.Type == unix.CGROUP2_SUPER_MAGIC
+}
+
+// hybridControllers returns the enabled controllers bound to a cgroup v1
+// hierarchy according to the contents of /proc/cgroups, and the
+// controllers available in the cgroup v2 hierarchy according to the
+// contents of its cgroup.controllers file.
+func hybridControllers(procCgroups, unifiedControllers string) (v1, v2 []string) {
+	for _, line := range strings.Split(procCgroups, "\n") {
+		// #subsys_name hierarchy num_cgroups enabled
+		fields := strings.Fields(line)
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[1] != "0" && fields[3] == "1" {
+			v1 = append(v1, fields[0])
+		}
+	}
+	return v1, strings.Fields(unifiedControllers)
+}
+
+// controllerList formats a list of cgroup controllers for a warning.
+func controllerList(controllers []string) string {
+	if len(controllers) == 0 {
+		return "none"
+	}
+	return strings.Join(controllers, ",")
+}
+
+// CRIUInfo describes the CRIU installation used to checkpoint and restore
+// containers on this host.
+func (r *Runtime) CRIUInfo() *define.CRIUInfo {
+	return criuInfo()
+}
+
+// criuInfo probes the CRIU binary used for checkpoint/restore.
+func criuInfo() *define.CRIUInfo {
+	info := &define.CRIUInfo{}
+	if path, err := exec.LookPath("criu"); err == nil {
+		info.Path = path
+	}
+	version, err := criu.GetCriuVersion()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Version = criuVersionString(version)
+	if version < criu.MinCriuVersion {
+		info.Error = fmt.Sprintf("checkpoint/restore requires at least CRIU %s", criuVersionString(criu.MinCriuVersion))
+		return info
+	}
+	info.Functional = true
+	return info
+}
+
+// criuVersionString converts a CRIU version number as reported by CRIU,
+// e.g. 31100, into the dotted form, e.g. 3.11.0.
+func criuVersionString(version int) string {
+	return fmt.Sprintf("%d.%d.%d", version/10000, version/100%100, version%100)
+}
+
+// rootlessNetworkCmdSelection returns where the rootless network command
+// cmd was resolved from and why it is used, given the paths the pasta and
+// slirp4netns binaries were found at.  Podman never falls back to the other
+// command, so a missing binary is reported instead.
+func rootlessNetworkCmdSelection(cmd, pastaPath, slirp4netnsPath string) (source, reason string) {
+	// pasta is the built-in default, slirp4netns is used if the option
+	// is set to an empty string.
+	if cmd == pasta.BinaryName {
+		source, reason = "default", "pasta is the default rootless network command"
+	} else {
+		source, reason = "config", fmt.Sprintf("default_rootless_network_cmd is set to %q in containers.conf", cmd)
+	}
+	switch cmd {
+	case pasta.BinaryName:
+		if pastaPath == "" {
+			reason += ", but the pasta binary was not found"
+		}
+	case slirp4netns.BinaryName, "":
+		if slirp4netnsPath == "" {
+			reason += ", but the slirp4netns binary was not found"
+		}
+	default:
+		reason += ", which is not a valid rootless network command"
+	}
+	return source, reason
+}
+
+// rootlessPortForwarder returns the component forwarding published ports
+// for containers using the default rootless network command.
+func (r *Runtime) rootlessPortForwarder() string {
+	switch r.config.Network.DefaultRootlessNetworkCmd {
+	case pasta.BinaryName:
+		// pasta forwards the ports itself
+		return pasta.BinaryName
+	case slirp4netns.BinaryName, "":
+		forwarder := rootlessport.BinaryName
+		// The last port_handler option wins, as in the slirp4netns setup
+		for _, o := range r.config.Engine.NetworkCmdOptions.Get() {
+			switch o {
+			case "port_handler=slirp4netns":
+				forwarder = slirp4netns.BinaryName
+			case "port_handler=rootlesskit":
+				forwarder = rootlessport.BinaryName
+			}
+		}
+		return forwarder
+	}
+	return ""
+}
+
+// defaultCapabilities normalizes the configured default capabilities and
+// returns them along with the known capabilities that are not among them.
+func defaultCapabilities(caps []string) (defaults, dropped []string) {
+	defaults = make([]string, 0, len(caps))
+	for _, c := range caps {
+		c = strings.ToUpper(c)
+		if !strings.HasPrefix(c, "CAP_") {
+			c = "CAP_" + c
+		}
+		defaults = append(defaults, c)
+	}
+	dropped = []string{}
+	for _, c := range capabilities.AllCapabilities() {
+		if !slices.Contains(defaults, c) {
+			dropped = append(dropped, c)
+		}
+	}
+	return defaults, dropped
+}
+
+// ociDefaultCapabilities and ociDefaultRlimits are the capabilities and
+// rlimits of the configuration generated by runc spec and crun spec.
+var (
+	ociDefaultCapabilities = []string{"CAP_AUDIT_WRITE", "CAP_KILL", "CAP_NET_BIND_SERVICE"}
+	ociDefaultRlimits      = map[string]string{"nofile": "1024:1024"}
+)
+
+// securityDefaultsInfo compares the default capabilities caps and the
+// default ulimits of containers to the OCI runtime defaults.
+func securityDefaultsInfo(caps, ulimits []string) *define.SecurityDefaultsInfo {
+	info := &define.SecurityDefaultsInfo{
+		AddedCapabilities:   []string{},
+		RemovedCapabilities: []string{},
+		Ulimits:             []define.UlimitDiffInfo{},
+	}
+	defaults, _ := defaultCapabilities(caps)
+	for _, c := range defaults {
+		if !slices.Contains(ociDefaultCapabilities, c) && !slices.Contains(info.AddedCapabilities, c) {
+			info.AddedCapabilities = append(info.AddedCapabilities, c)
+		}
+	}
+	for _, c := range ociDefaultCapabilities {
+		if !slices.Contains(defaults, c) {
+			info.RemovedCapabilities = append(info.RemovedCapabilities, c)
+		}
+	}
+
+	podman := map[string]string{}
+	for _, u := range ulimits {
+		ulimit, err := units.ParseUlimit(u)
+		if err != nil {
+			logrus.Debugf("Parsing default ulimit %q: %v", u, err)
+			continue
+		}
+		podman[ulimit.Name] = rlimitString(ulimit.Soft) + ":" + rlimitString(ulimit.Hard)
+	}
+	names := make([]string, 0, len(podman)+len(ociDefaultRlimits))
+	for name := range podman {
+		names = append(names, name)
+	}
+	for name := range ociDefaultRlimits {
+		if _, ok := podman[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		diff := define.UlimitDiffInfo{Name: name, OCI: ociDefaultRlimits[name], Podman: podman[name]}
+		if diff.Podman == "" {
+			diff.Podman = "inherited"
+		}
+		if diff.OCI != diff.Podman {
+			info.Ulimits = append(info.Ulimits, diff)
+		}
+	}
+	return info
+}
+
+// rlimitString formats a ulimit value, where -1 means unlimited.
+func rlimitString(limit int64) string {
+	if limit < 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+// defaultMountsFile returns the mounts.conf file used for the default
+// mounts of containers, the same way the subscriptions package looks it up,
+// or "" if there is none.
+func defaultMountsFile(configured string, isRootless bool) string {
+	files := []string{configured}
+	if configured == "" {
+		files = []string{subscriptions.OverrideMountsFile, subscriptions.DefaultMountsFile}
+		if isRootless {
+			files = append([]string{subscriptions.UserOverrideMountsFile}, files...)
+		}
+	}
+	for _, file := range files {
+		if err := fileutils.Exists(file); err == nil {
+			return file
+		}
+	}
+	return ""
+}
+
+// countDefaultMounts counts the entries of the mounts.conf file at path
+// whose host path exists and is therefore mounted into containers.
+func countDefaultMounts(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("Unable to read default mounts: %v", err)
+		return 0
+	}
+	defer f.Close()
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "/") {
+			continue
+		}
+		hostPath, _, _ := strings.Cut(line, ":")
+		if err := fileutils.Exists(hostPath); err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// slirp4netnsSandboxed reports whether the slirp4netns binary at path is
+// started with its sandbox, mirroring the feature checks done when setting
+// up the network: --enable-sandbox is only used with pivot_root, while
+// --enable-seccomp is used whenever the binary supports it.
+func (r *Runtime) slirp4netnsSandboxed(path string) bool {
+	out, err := exec.Command(path, "--help").CombinedOutput()
+	if err != nil {
+		logrus.Warnf("Failed to retrieve the supported options of %s: %v", path, err)
+		return false
+	}
+	help := string(out)
+	if !r.config.Engine.NoPivotRoot && strings.Contains(help, "--enable-sandbox") {
+		return true
+	}
+	return strings.Contains(help, "--enable-seccomp")
+}
+
+// invocationID is $INVOCATION_ID as set by systemd when starting Podman.
+// It is captured early as the API service unsets it once it is running.
+var invocationID = os.Getenv("INVOCATION_ID")
+
+// systemdUnitInfo returns the systemd service unit the current process runs
+// in, or nil if it is not running in one.
+func systemdUnitInfo() *define.SystemdUnitInfo {
+	var name string
+	if cgroup, err := cgroups.GetOwnCgroup(); err != nil {
+		logrus.Debugf("Reading own cgroup: %v", err)
+	} else {
+		name = serviceUnitFromCgroup(cgroup)
+	}
+	if name == "" && invocationID == "" {
+		return nil
+	}
+	return &define.SystemdUnitInfo{
+		Name:         name,
+		InvocationID: invocationID,
+	}
+}
+
+// serviceUnitFromCgroup returns the name of the service unit owning the
+// cgroup, or an empty string if the cgroup does not belong to a service.
+func serviceUnitFromCgroup(cgroup string) string {
+	unit := filepath.Base(cgroup)
+	if !strings.HasSuffix(unit, ".service") {
+		return ""
+	}
+	return unit
+}
+
+// idMappedMountsSupported returns whether idmapped mounts can be created.
+// The kernel must provide mount_setattr, which is probed with an invalid
+// file descriptor rather than by creating a mount, and creating them needs
+// privileges rootless users do not have.  Whether the file systems in use
+// support them is not checked.
+func idMappedMountsSupported() bool {
+	if rootless.IsRootless() {
+		return false
+	}
+	// A kernel with mount_setattr rejects the descriptor, without it
+	// or if it is filtered by seccomp the call fails differently.
+	err := unix.MountSetattr(-1, "", unix.AT_EMPTY_PATH, &unix.MountAttr{})
+	if !errors.Is(err, unix.EBADF) {
+		logrus.Debugf("Idmapped mounts are not supported: %v", err)
+		return false
+	}
+	return true
+}
+
+// helperBinaries are the helper binaries looked up in helper_binaries_dir,
+// along with whether $PATH is searched as a fallback.
+var helperBinaries = []struct {
+	name       string
+	searchPATH bool
+}{
+	{"aardvark-dns", false},
+	{"catatonit", true},
+	{"netavark", false},
+	{pasta.BinaryName, true},
+	{rootlessport.BinaryName, false},
+	{slirp4netns.BinaryName, true},
+}
+
+// helperBinariesInfo reports where each helper binary was found using the
+// same lookup as FindHelperBinary.
+func (r *Runtime) helperBinariesInfo() []define.HelperBinaryInfo {
+	dirs := r.config.Engine.HelperBinariesDir.Get()
+	if dir, found := os.LookupEnv("CONTAINERS_HELPER_BINARY_DIR"); found {
+		dirs = append([]string{dir}, dirs...)
+	}
+	helpers := make([]define.HelperBinaryInfo, 0, len(helperBinaries))
+	for _, helper := range helperBinaries {
+		path, err := r.config.FindHelperBinary(helper.name, helper.searchPATH)
+		if err != nil {
+			logrus.Debugf("Helper binary %s not found: %v", helper.name, err)
+		}
+		helpers = append(helpers, define.HelperBinaryInfo{
+			Name:       helper.name,
+			SearchDirs: dirs,
+			SearchPath: helper.searchPATH,
+			Path:       path,
+		})
+	}
+	return helpers
+}
+
+func (r *Runtime) setPlatformStoreInfo(info *define.StoreInfo) {
+	info.GraphRootLockHolder = lockHolder(info.GraphRootLock)
+	r.setMountStoreInfo(info)
+	if info.GraphDriverName == "overlay" {
+		r.setOverlayStoreInfo(info)
+	}
+}
+
+// isTmpfs returns whether path is on a tmpfs.
+func isTmpfs(path string) (bool, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false, err
+	}
+	return st.Type == unix.TMPFS_MAGIC, nil
+}
+
+// networkFilesystems maps the magic numbers of network file systems to
+// their names.
+var networkFilesystems = map[uint32]string{
+	unix.NFS_SUPER_MAGIC: "nfs",
+	unix.SMB_SUPER_MAGIC: "smb",
+	0xff534d42:           "cifs",
+	0xfe534d42:           "smb2",
+	unix.AFS_SUPER_MAGIC: "afs",
+	0x00c36400:           "ceph",
+}
+
+// networkFilesystem returns the name of the network file system path is
+// on, or an empty string if it is not on a network file system.
+func networkFilesystem(path string) (string, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	// The magic numbers are unsigned, but the type is signed on 32 bit.
+	return networkFilesystems[uint32(st.Type)], nil
+}
+
+// mountWarnRatio is the share of the mount limit in use above which info
+// warns about running out of mounts.
+const mountWarnRatio = 0.9
+
+func (r *Runtime) setMountStoreInfo(info *define.StoreInfo) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		logrus.Debugf("Unable to read mounts: %v", err)
+		return
+	}
+	defer f.Close()
+	info.MountCount, info.StorageMountCount, err = countMounts(f, info.GraphRoot, info.RunRoot)
+	if err != nil {
+		logrus.Debugf("Unable to read mounts: %v", err)
+		return
+	}
+
+	val, err := os.ReadFile("/proc/sys/fs/mount-max")
+	if err != nil {
+		logrus.Debugf("Unable to read mount limit: %v", err)
+		return
+	}
+	if info.MountLimit, err = strconv.Atoi(strings.TrimSpace(string(val))); err != nil {
+		logrus.Debugf("Unable to parse mount limit %q: %v", val, err)
+		return
+	}
+	if float64(info.MountCount) >= mountWarnRatio*float64(info.MountLimit) {
+		logrus.Warnf("%d of at most %d mounts are in use, mounting the root file systems of containers may fail soon", info.MountCount, info.MountLimit)
+	}
+}
+
+// countMounts counts the mounts listed in the mountinfo format and how many
+// of them are mounted below one of dirs.
+func countMounts(mountinfo io.Reader, dirs ...string) (total, below int, err error) {
+	scanner := bufio.NewScanner(mountinfo)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		total++
+		mountPoint := fields[4]
+		for _, dir := range dirs {
+			if dir != "" && (mountPoint == dir || strings.HasPrefix(mountPoint, dir+"/")) {
+				below++
+				break
+			}
+		}
+	}
+	return total, below, scanner.Err()
+}
+
+// overlayVolatileSupported returns whether the kernel overlay supports the
+// volatile mount option, as recorded in runhome by the storage library the
+// first time it mounted a layer with it, or nil if it has not checked yet.
+func overlayVolatileSupported(runhome string) *bool {
+	var supported bool
+	switch {
+	case fileutils.Exists(filepath.Join(runhome, "volatile-true")) == nil:
+		supported = true
+	case fileutils.Exists(filepath.Join(runhome, "volatile-false")) == nil:
+		supported = false
+	default:
+		return nil
+	}
+	return &supported
+}
+
+// lockHolder returns the PID of a process holding a lock on the specified
+// lock file, or 0 if it is not locked by another process.
+func lockHolder(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("Unable to open lock file %s: %v", path, err)
+		return 0
+	}
+	defer f.Close()
+	lk := unix.Flock_t{
+		Type:   unix.F_WRLCK,
+		Whence: int16(unix.SEEK_SET),
+	}
+	if err := unix.FcntlFlock(f.Fd(), unix.F_GETLK, &lk); err != nil {
+		logrus.Debugf("Unable to query lock file %s: %v", path, err)
+		return 0
+	}
+	if lk.Type == unix.F_UNLCK {
+		return 0
+	}
+	return int(lk.Pid)
+}
+
+func (r *Runtime) setOverlayStoreInfo(info *define.StoreInfo) {
+	var mountOpts []string
+	for _, o := range r.store.GraphOptions() {
+		if key, val, found := strings.Cut(o, "="); found && strings.HasSuffix(key, "mountopt") {
+			mountOpts = append(mountOpts, strings.Split(val, ",")...)
+		}
+	}
+	configured := func(name string) string {
+		for _, o := range mountOpts {
+			if key, val, found := strings.Cut(o, "="); found && key == name {
+				return val
+			}
+		}
+		return ""
+	}
+
+	metacopy := &define.OverlayOption{
+		Configured: configured("metacopy"),
+		Effective:  "off",
+	}
+	if info.GraphStatus["Using metacopy"] == "true" {
+		metacopy.Effective = "on"
+	}
+	info.OverlayMetacopy = metacopy
+
+	// The storage library passes redirect_dir on to the kernel as is, so
+	// unless configured the kernel default applies.  metacopy implies it.
+	redirectDir := &define.OverlayOption{
+		Configured: configured("redirect_dir"),
+		Effective:  "off",
+	}
+	switch {
+	case metacopy.Effective == "on":
+		redirectDir.Effective = "on"
+	case redirectDir.Configured != "":
+		// "follow" and "nofollow" do not create redirects
+		if redirectDir.Configured == "on" {
+			redirectDir.Effective = "on"
+		}
+	default:
+		if val, err := os.ReadFile("/sys/module/overlay/parameters/redirect_dir"); err == nil && strings.TrimSpace(string(val)) == "Y" {
+			redirectDir.Effective = "on"
+		}
+	}
+	info.OverlayRedirectDir = redirectDir
+
+	nativeDiff := info.GraphStatus["Native Overlay Diff"] == "true"
+	info.NativeOverlayDiff = &nativeDiff
+	info.VolatileOverlaySupported = overlayVolatileSupported(filepath.Join(info.RunRoot, "overlay"))
+	// The version of the mount program was already queried for the
+	// graph options
+	for key, val := range info.GraphOptions {
+		program, ok := val.(map[string]interface{})
+		if !ok || !strings.HasSuffix(key, "mount_program") {
+			continue
+		}
+		mountProgram := &define.MountProgramInfo{}
+		mountProgram.Executable, _ = program["Executable"].(string)
+		mountProgram.Package, _ = program["Package"].(string)
+		mountProgram.Version, _ = program["Version"].(string)
+		info.OverlayMountProgram = mountProgram
+	}
+}
+
+// idMappingMode classifies the rootless UID mappings by the number of
+// mapped IDs.
+func idMappingMode(uidMap []idtools.IDMap) string {
+	size := 0
+	for _, m := range uidMap {
+		size += m.Size
+	}
+	switch {
+	case size == 0:
+		return "none"
+	case size == 1:
+		return "single"
+	default:
+		return "full"
+	}
+}
+
+// userNSInfo describes the user namespace of the current process.
+func userNSInfo() *define.UserNSInfo {
+	uidMap, gidMap, err := unshare.GetHostIDMappings("")
+	if err != nil {
+		logrus.Debugf("Reading id mappings: %v", err)
+		return nil
+	}
+	info := &define.UserNSInfo{
+		UIDMap: util.RuntimeSpecToIDtools(uidMap),
+		GIDMap: util.RuntimeSpecToIDtools(gidMap),
+	}
+	info.Mode = userNSMode(info.UIDMap, rootless.IsRootless())
+	return info
+}
+
+// userNSMode classifies the user namespace with the given UID mappings.
+func userNSMode(uidMap []idtools.IDMap, isRootless bool) string {
+	// The initial user namespace maps the full 32 bit range onto itself
+	if len(uidMap) == 1 && uidMap[0].ContainerID == 0 && uidMap[0].HostID == 0 && uint32(uidMap[0].Size) == math.MaxUint32 {
+		return "host"
+	}
+	if isRootless {
+		return "rootless"
+	}
+	return "nested"
+}
+
+// fipsEnabled returns true if the kernel runs in FIPS mode.
+func fipsEnabled() bool {
+	data, err := os.ReadFile("/proc/sys/crypto/fips_enabled")
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("Reading FIPS mode: %v", err)
+		}
+		return false
+	}
+	return strings.TrimSpace(string(data)) == "1"
+}
+
+// keyringAvailable probes the session keyring.  The keyctl syscalls are
+// often blocked by seccomp in containers and on some VM hosts.
+func keyringAvailable() bool {
+	if _, err := unix.KeyctlGetKeyringID(unix.KEY_SPEC_SESSION_KEYRING, false); err != nil && !errors.Is(err, unix.ENOKEY) {
+		logrus.Debugf("Kernel keyring is not available: %v", err)
+		return false
+	}
+	return true
+}
+
 func statToPercent(stats []string) (*define.CPUUsage, error) {
 	userTotal, err := strconv.ParseFloat(stats[1], 64)
 	if err != nil {
@@ -120,13 +886,22 @@ func statToPercent(stats []string) (*define.CPUUsage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse idle value %q: %w", stats[4], err)
 	}
+	s := timesToPercent(userTotal, systemTotal, idleTotal)
+	return &s, nil
+}
+
+// timesToPercent converts user, system and idle times into percentages
+// rounded to two decimal places.
+func timesToPercent(userTotal, systemTotal, idleTotal float64) define.CPUUsage {
 	total := userTotal + systemTotal + idleTotal
-	s := define.CPUUsage{
+	if total == 0 {
+		return define.CPUUsage{IdlePercent: 100}
+	}
+	return define.CPUUsage{
 		UserPercent:   math.Round((userTotal/total*100)*100) / 100,
 		SystemPercent: math.Round((systemTotal/total*100)*100) / 100,
 		IdlePercent:   math.Round((idleTotal/total*100)*100) / 100,
 	}
-	return &s, nil
 }
 
 // getCPUUtilization Returns a CPUUsage object that summarizes CPU
@@ -146,3 +921,57 @@ func getCPUUtilization() (*define.CPUUsage, error) {
 	stats := strings.Fields(scanner.Text())
 	return statToPercent(stats)
 }
+
+// readPerCPUTimes returns the user, system and idle times of every CPU
+// listed in /proc/stat, in the order they appear.
+func readPerCPUTimes() ([][3]float64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var times [][3]float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Skip the aggregate "cpu" line and everything that is not a "cpuN" line
+		stats := strings.Fields(scanner.Text())
+		if len(stats) < 5 || stats[0] == "cpu" || !strings.HasPrefix(stats[0], "cpu") {
+			continue
+		}
+		var cpu [3]float64
+		// column 1 is user, column 3 is system, column 4 is idle
+		for i, column := range []int{1, 3, 4} {
+			cpu[i], err = strconv.ParseFloat(stats[column], 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s value %q: %w", stats[0], stats[column], err)
+			}
+		}
+		times = append(times, cpu)
+	}
+	return times, scanner.Err()
+}
+
+// getPerCPUUtilization returns the utilization of each CPU, computed from
+// two samples of /proc/stat taken perCPUSampleInterval apart.
+func getPerCPUUtilization() ([]define.CPUUsage, error) {
+	before, err := readPerCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(perCPUSampleInterval)
+	after, err := readPerCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	if len(before) != len(after) {
+		return nil, errors.New("number of online CPUs changed while sampling CPU utilization")
+	}
+	usage := make([]define.CPUUsage, 0, len(after))
+	for i := range after {
+		usage = append(usage, timesToPercent(after[i][0]-before[i][0], after[i][1]-before[i][1], after[i][2]-before[i][2]))
+	}
+	return usage, nil
+}
+
+// systemRegistriesConfDirPath is the system registries.conf.d directory.
+const systemRegistriesConfDirPath = "/etc/containers/registries.conf.d"
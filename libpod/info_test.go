@@ -4,10 +4,22 @@ package libpod
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/containers/common/pkg/capabilities"
+	"github.com/containers/image/v5/types"
 	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/util"
+	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/homedir"
+	"github.com/containers/storage/pkg/idtools"
+	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_statToPercent(t *testing.T) {
@@ -59,3 +71,362 @@ func Test_statToPercent(t *testing.T) {
 		})
 	}
 }
+
+func Test_idMappingMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		uidMap []idtools.IDMap
+		want   string
+	}{
+		{
+			name:   "NoMapping",
+			uidMap: nil,
+			want:   "none",
+		},
+		{
+			name:   "SingleMapping",
+			uidMap: []idtools.IDMap{{ContainerID: 0, HostID: 1000, Size: 1}},
+			want:   "single",
+		},
+		{
+			name: "FullMapping",
+			uidMap: []idtools.IDMap{
+				{ContainerID: 0, HostID: 1000, Size: 1},
+				{ContainerID: 1, HostID: 100000, Size: 65536},
+			},
+			want: "full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, idMappingMode(tt.uidMap))
+		})
+	}
+}
+
+func Test_userNSMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		uidMap     []idtools.IDMap
+		isRootless bool
+		want       string
+	}{
+		{
+			name:   "InitialNamespace",
+			uidMap: util.RuntimeSpecToIDtools([]specs.LinuxIDMapping{{ContainerID: 0, HostID: 0, Size: math.MaxUint32}}),
+			want:   "host",
+		},
+		{
+			name:       "Rootless",
+			uidMap:     []idtools.IDMap{{ContainerID: 0, HostID: 1000, Size: 1}},
+			isRootless: true,
+			want:       "rootless",
+		},
+		{
+			name:   "RootInContainer",
+			uidMap: []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}},
+			want:   "nested",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, userNSMode(tt.uidMap, tt.isRootless))
+		})
+	}
+}
+
+func Test_serviceUnitFromCgroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{
+			name:   "SystemService",
+			cgroup: "/system.slice/podman.service",
+			want:   "podman.service",
+		},
+		{
+			name:   "UserService",
+			cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/podman-kube@foo.service",
+			want:   "podman-kube@foo.service",
+		},
+		{
+			name:   "ScopeBelowUserManager",
+			cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/app-terminal.scope",
+			want:   "",
+		},
+		{
+			name:   "LoginSession",
+			cgroup: "/user.slice/user-1000.slice/session-2.scope",
+			want:   "",
+		},
+		{
+			name:   "RootCgroup",
+			cgroup: "/",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, serviceUnitFromCgroup(tt.cgroup))
+		})
+	}
+}
+
+func Test_criuVersionString(t *testing.T) {
+	assert.Equal(t, "3.11.0", criuVersionString(31100))
+	assert.Equal(t, "3.19.1", criuVersionString(31901))
+	assert.Equal(t, "4.0.0", criuVersionString(40000))
+}
+
+func Test_timezoneFromPath(t *testing.T) {
+	assert.Equal(t, "Europe/Berlin", timezoneFromPath("/usr/share/zoneinfo/Europe/Berlin"))
+	assert.Equal(t, "UTC", timezoneFromPath("/usr/share/zoneinfo/UTC"))
+	assert.Equal(t, "America/New_York", timezoneFromPath("/usr/share/zoneinfo/right/America/New_York"))
+	assert.Equal(t, "", timezoneFromPath("/etc/localtime"))
+}
+
+func Test_registriesConfPaths(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "registries.conf")
+	dropInDir := filepath.Join(dir, "registries.conf.d")
+	require.NoError(t, os.Mkdir(dropInDir, 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dropInDir, "sub.conf"), 0o755))
+	for _, name := range []string{"b.conf", "a.conf", "ignored.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dropInDir, name), nil, 0o644))
+	}
+	sys := &types.SystemContext{
+		SystemRegistriesConfPath:    conf,
+		SystemRegistriesConfDirPath: dropInDir,
+	}
+
+	paths, err := registriesConfPaths(sys)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dropInDir, "a.conf"), filepath.Join(dropInDir, "b.conf")}, paths)
+
+	require.NoError(t, os.WriteFile(conf, nil, 0o644))
+	paths, err = registriesConfPaths(sys)
+	require.NoError(t, err)
+	assert.Equal(t, []string{conf, filepath.Join(dropInDir, "a.conf"), filepath.Join(dropInDir, "b.conf")}, paths)
+
+}
+
+func Test_registriesConfDirs(t *testing.T) {
+	sys := &types.SystemContext{SystemRegistriesConfDirPath: "/conf.d"}
+	assert.Equal(t, []string{"/conf.d"}, registriesConfDirs(sys))
+
+	// Without an explicit directory, the system and the user directory
+	// are both read.
+	sys = &types.SystemContext{
+		SystemRegistriesConfPath:     "/registries.conf",
+		RootForImplicitAbsolutePaths: "/root",
+	}
+	userDir := filepath.Join(homedir.Get(), ".config", "containers", "registries.conf.d")
+	assert.Equal(t, []string{filepath.Join("/root", systemRegistriesConfDirPath), userDir}, registriesConfDirs(sys))
+}
+
+func Test_countMounts(t *testing.T) {
+	mountinfo := `22 1 0:21 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 0:22 / /proc rw,nosuid,nodev,noexec,relatime shared:2 - proc proc rw
+24 22 0:23 / /var/lib/containers/storage/overlay rw,relatime - ext4 /dev/sda1 rw
+25 24 0:24 / /var/lib/containers/storage/overlay/abc/merged rw,relatime - overlay overlay rw
+26 22 0:25 / /run/containers/storage/overlay-containers/abc/userdata/shm rw - tmpfs shm rw
+27 22 0:26 / /var/lib/containers/storage2 rw,relatime - ext4 /dev/sdb1 rw
+`
+	total, below, err := countMounts(strings.NewReader(mountinfo), "/var/lib/containers/storage", "/run/containers/storage")
+	require.NoError(t, err)
+	assert.Equal(t, 6, total)
+	assert.Equal(t, 3, below)
+}
+
+func Test_defaultCapabilities(t *testing.T) {
+	defaults, dropped := defaultCapabilities([]string{"CAP_CHOWN", "kill", "NET_BIND_SERVICE"})
+	assert.Equal(t, []string{"CAP_CHOWN", "CAP_KILL", "CAP_NET_BIND_SERVICE"}, defaults)
+	assert.Contains(t, dropped, "CAP_SYS_ADMIN")
+	assert.NotContains(t, dropped, "CAP_KILL")
+	assert.Len(t, dropped, len(capabilities.AllCapabilities())-3)
+}
+
+func Test_additionalStoreImages(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for store, ids := range map[string][]string{first: {"a", "b"}, second: {"b", "c", "d"}} {
+		for _, id := range ids {
+			require.NoError(t, os.MkdirAll(filepath.Join(store, "overlay-images", id), 0o755))
+		}
+	}
+	images := []storage.Image{
+		{ID: "a", ReadOnly: true},
+		{ID: "b", ReadOnly: true},
+		{ID: "c", ReadOnly: true},
+		{ID: "d", ReadOnly: true},
+		// Images of the graph root are not counted, even if an
+		// additional store has one with the same ID.
+		{ID: "e"},
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(second, "overlay-images", "e"), 0o755))
+	assert.Equal(t, []int{2, 2}, additionalStoreImages([]string{first, second}, "overlay", images))
+}
+
+func Test_defaultMounts(t *testing.T) {
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "secrets")
+	require.NoError(t, os.Mkdir(secrets, 0o755))
+	conf := filepath.Join(dir, "mounts.conf")
+	content := fmt.Sprintf("# comment\n%s:/run/secrets\n%s\n/does/not/exist:/run/missing\nrelative:/run/relative\n", secrets, dir)
+	require.NoError(t, os.WriteFile(conf, []byte(content), 0o644))
+
+	assert.Equal(t, conf, defaultMountsFile(conf, false))
+	assert.Equal(t, "", defaultMountsFile(filepath.Join(dir, "missing.conf"), true))
+	assert.Equal(t, 2, countDefaultMounts(conf))
+}
+
+func Test_findPlugins(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(first, "bridge"), nil, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(first, "README"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "bridge"), nil, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "macvlan"), nil, 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(second, "macvlan"), filepath.Join(second, "ipvlan")))
+
+	plugins := findPlugins([]string{filepath.Join(first, "missing"), first, second})
+	assert.Equal(t, []string{
+		filepath.Join(first, "bridge"),
+		filepath.Join(second, "ipvlan"),
+		filepath.Join(second, "macvlan"),
+	}, plugins)
+	assert.Empty(t, findPlugins(nil))
+}
+
+func Test_storageWarnings(t *testing.T) {
+	dir := t.TempDir()
+	graphRoot := filepath.Join(dir, "graph")
+	runRoot := filepath.Join(dir, "run")
+	require.NoError(t, os.Mkdir(graphRoot, 0o755))
+	require.NoError(t, os.Mkdir(runRoot, 0o755))
+
+	warnings := storageWarnings(graphRoot, runRoot)
+	assert.Contains(t, warnings, fmt.Sprintf("runRoot %s and graphRoot %s share a file system, runtime state and images compete for space on it", runRoot, graphRoot))
+
+	fs, err := networkFilesystem(dir)
+	require.NoError(t, err)
+	assert.Empty(t, fs)
+}
+
+func Test_pidsInUse(t *testing.T) {
+	inUse, err := pidsInUse("0.11 0.14 0.10 2/611 4242\n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(611), inUse)
+
+	_, err = pidsInUse("0.11 0.14 0.10")
+	assert.Error(t, err)
+}
+
+func Test_cgroupPidsLimit(t *testing.T) {
+	root := t.TempDir()
+	session := filepath.Join(root, "user.slice", "user-1000.slice", "session-1.scope")
+	require.NoError(t, os.MkdirAll(session, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(session, "pids.max"), []byte("max\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(session, "pids.current"), []byte("12\n"), 0o644))
+
+	limit, current := cgroupPidsLimit(root, "/user.slice/user-1000.slice/session-1.scope")
+	assert.Equal(t, int64(0), limit)
+	assert.Equal(t, int64(0), current)
+
+	user := filepath.Dir(session)
+	require.NoError(t, os.WriteFile(filepath.Join(user, "pids.max"), []byte("100\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(user, "pids.current"), []byte("42\n"), 0o644))
+	limit, current = cgroupPidsLimit(root, "/user.slice/user-1000.slice/session-1.scope")
+	assert.Equal(t, int64(100), limit)
+	assert.Equal(t, int64(42), current)
+}
+
+func Test_hybridControllers(t *testing.T) {
+	procCgroups := `#subsys_name	hierarchy	num_cgroups	enabled
+cpuset	4	1	1
+cpu	2	66	1
+cpuacct	2	66	1
+memory	7	108	1
+pids	0	1	1
+rdma	0	1	0
+`
+	v1, v2 := hybridControllers(procCgroups, "pids\n")
+	assert.Equal(t, []string{"cpuset", "cpu", "cpuacct", "memory"}, v1)
+	assert.Equal(t, []string{"pids"}, v2)
+
+	v1, v2 = hybridControllers("", "")
+	assert.Empty(t, v1)
+	assert.Empty(t, v2)
+	assert.Equal(t, "none", controllerList(v2))
+}
+
+func Test_rootlessNetworkCmdSelection(t *testing.T) {
+	source, reason := rootlessNetworkCmdSelection("pasta", "/usr/bin/pasta", "")
+	assert.Equal(t, "default", source)
+	assert.Equal(t, "pasta is the default rootless network command", reason)
+
+	source, reason = rootlessNetworkCmdSelection("pasta", "", "/usr/bin/slirp4netns")
+	assert.Equal(t, "default", source)
+	assert.Equal(t, "pasta is the default rootless network command, but the pasta binary was not found", reason)
+
+	source, reason = rootlessNetworkCmdSelection("slirp4netns", "/usr/bin/pasta", "")
+	assert.Equal(t, "config", source)
+	assert.Equal(t, `default_rootless_network_cmd is set to "slirp4netns" in containers.conf, but the slirp4netns binary was not found`, reason)
+
+	_, reason = rootlessNetworkCmdSelection("invalid", "/usr/bin/pasta", "/usr/bin/slirp4netns")
+	assert.Equal(t, `default_rootless_network_cmd is set to "invalid" in containers.conf, which is not a valid rootless network command`, reason)
+}
+
+func Test_securityDefaultsInfo(t *testing.T) {
+	info := securityDefaultsInfo([]string{"CHOWN", "cap_kill", "NET_BIND_SERVICE"}, []string{"nproc=2048:4096", "nofile=1024:1024", "core=-1"})
+	assert.Equal(t, []string{"CAP_CHOWN"}, info.AddedCapabilities)
+	assert.Equal(t, []string{"CAP_AUDIT_WRITE"}, info.RemovedCapabilities)
+	assert.Equal(t, []define.UlimitDiffInfo{
+		{Name: "core", Podman: "unlimited:unlimited"},
+		{Name: "nproc", Podman: "2048:4096"},
+	}, info.Ulimits)
+
+	info = securityDefaultsInfo([]string{"CAP_AUDIT_WRITE", "CAP_KILL", "CAP_NET_BIND_SERVICE"}, nil)
+	assert.Empty(t, info.AddedCapabilities)
+	assert.Empty(t, info.RemovedCapabilities)
+	assert.Equal(t, []define.UlimitDiffInfo{{Name: "nofile", OCI: "1024:1024", Podman: "inherited"}}, info.Ulimits)
+}
+
+func Test_overlayVolatileSupported(t *testing.T) {
+	runhome := t.TempDir()
+	assert.Nil(t, overlayVolatileSupported(runhome))
+
+	require.NoError(t, os.WriteFile(filepath.Join(runhome, "volatile-true"), nil, 0o644))
+	supported := overlayVolatileSupported(runhome)
+	require.NotNil(t, supported)
+	assert.True(t, *supported)
+
+	runhome = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(runhome, "volatile-false"), nil, 0o644))
+	supported = overlayVolatileSupported(runhome)
+	require.NotNil(t, supported)
+	assert.False(t, *supported)
+}
+
+func Test_effectiveHooks(t *testing.T) {
+	low := t.TempDir()
+	high := t.TempDir()
+	hook := func(stage string) []byte {
+		return []byte(fmt.Sprintf(`{"version":"1.0.0","hook":{"path":"/bin/sh"},"when":{"always":true},"stages":[%q]}`, stage))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(low, "b.json"), hook("prestart"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(low, "c.json"), hook("poststop"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(high, "A.json"), hook("poststart"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(high, "b.json"), hook("createRuntime"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(high, "README"), nil, 0o644))
+
+	assert.Equal(t, []define.HookInfo{
+		{Name: "A.json", Path: filepath.Join(high, "A.json"), Stages: []string{"poststart"}},
+		{Name: "b.json", Path: filepath.Join(high, "b.json"), Stages: []string{"createRuntime"}, Overrides: []string{filepath.Join(low, "b.json")}},
+		{Name: "c.json", Path: filepath.Join(low, "c.json"), Stages: []string{"poststop"}},
+	}, effectiveHooks([]string{filepath.Join(low, "missing"), low, high}, false))
+
+	implicit := effectiveHooks([]string{low, high}, true)
+	assert.Len(t, implicit, 4)
+}
@@ -0,0 +1,83 @@
+package lock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the lock acquisition counters of a StatsManager.
+type Stats struct {
+	// Acquisitions is the number of locks acquired
+	Acquisitions uint64
+	// MaxWait is the longest time spent waiting to acquire a lock
+	MaxWait time.Duration
+}
+
+// StatsManager is a Manager counting how often the locks it returns are
+// acquired and how long acquiring them takes.  The counters are kept in
+// this process only.
+type StatsManager struct {
+	Manager
+	acquisitions atomic.Uint64
+	maxWait      atomic.Int64
+}
+
+// NewStatsManager wraps manager to count the lock acquisitions.
+func NewStatsManager(manager Manager) *StatsManager {
+	return &StatsManager{Manager: manager}
+}
+
+// Stats returns the counters of the locks acquired so far.
+func (m *StatsManager) Stats() Stats {
+	return Stats{
+		Acquisitions: m.acquisitions.Load(),
+		MaxWait:      time.Duration(m.maxWait.Load()),
+	}
+}
+
+// AllocateLock allocates a new lock from the wrapped manager.
+func (m *StatsManager) AllocateLock() (Locker, error) {
+	return m.wrap(m.Manager.AllocateLock())
+}
+
+// RetrieveLock retrieves a lock from the wrapped manager.
+func (m *StatsManager) RetrieveLock(id uint32) (Locker, error) {
+	return m.wrap(m.Manager.RetrieveLock(id))
+}
+
+// AllocateAndRetrieveLock allocates and retrieves a lock from the wrapped
+// manager.
+func (m *StatsManager) AllocateAndRetrieveLock(id uint32) (Locker, error) {
+	return m.wrap(m.Manager.AllocateAndRetrieveLock(id))
+}
+
+func (m *StatsManager) wrap(lock Locker, err error) (Locker, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &statsLock{Locker: lock, manager: m}, nil
+}
+
+// record counts an acquisition that waited for wait.
+func (m *StatsManager) record(wait time.Duration) {
+	m.acquisitions.Add(1)
+	for {
+		current := m.maxWait.Load()
+		if int64(wait) <= current || m.maxWait.CompareAndSwap(current, int64(wait)) {
+			return
+		}
+	}
+}
+
+// statsLock is a Locker whose acquisitions are counted by its manager.
+type statsLock struct {
+	Locker
+	manager *StatsManager
+}
+
+// Lock locks the wrapped lock.
+func (l *statsLock) Lock() {
+	start := time.Now()
+	l.Locker.Lock()
+	l.manager.record(time.Since(start))
+}
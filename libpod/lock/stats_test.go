@@ -0,0 +1,36 @@
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsManager(t *testing.T) {
+	inMemory, err := NewInMemoryManager(4)
+	require.NoError(t, err)
+	manager := NewStatsManager(inMemory)
+	assert.Equal(t, Stats{}, manager.Stats())
+
+	lock, err := manager.AllocateLock()
+	require.NoError(t, err)
+	lock.Lock()
+	lock.Unlock()
+
+	// A retrieved lock is the same lock and is counted as well.
+	retrieved, err := manager.RetrieveLock(lock.ID())
+	require.NoError(t, err)
+	lock.Lock()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Unlock()
+	}()
+	retrieved.Lock()
+	retrieved.Unlock()
+
+	stats := manager.Stats()
+	assert.Equal(t, uint64(3), stats.Acquisitions)
+	assert.GreaterOrEqual(t, stats.MaxWait, 40*time.Millisecond)
+}
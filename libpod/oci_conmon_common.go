@@ -971,10 +971,14 @@ func (r *ConmonOCIRuntime) RuntimeInfo() (*define.ConmonInfo, *define.OCIRuntime
 		Version: conmonVersion,
 	}
 	ocirt := define.OCIRuntimeInfo{
-		Name:    r.name,
-		Path:    r.path,
-		Package: runtimePackage,
-		Version: runtimeVersion,
+		Name:              r.name,
+		Path:              r.path,
+		Package:           runtimePackage,
+		Version:           runtimeVersion,
+		SupportsJSON:      r.supportsJSON,
+		SupportsKVM:       r.supportsKVM,
+		SupportsNoCgroups: r.supportsNoCgroups,
+		NoPivotRoot:       r.noPivot,
 	}
 	return &conmon, &ocirt, nil
 }
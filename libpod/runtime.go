@@ -35,8 +35,10 @@ import (
 	"github.com/containers/podman/v5/pkg/rootless"
 	"github.com/containers/podman/v5/pkg/systemd"
 	"github.com/containers/podman/v5/pkg/util"
+	"github.com/containers/podman/v5/version"
 	"github.com/containers/storage"
 	"github.com/containers/storage/pkg/fileutils"
+	"github.com/containers/storage/pkg/ioutils"
 	"github.com/containers/storage/pkg/lockfile"
 	"github.com/containers/storage/pkg/unshare"
 	"github.com/docker/docker/pkg/namesgenerator"
@@ -81,6 +83,8 @@ type Runtime struct {
 	libimageRuntime        *libimage.Runtime
 	libimageEventsShutdown chan bool
 	lockManager            lock.Manager
+	// lockStats counts the acquisitions of the locks of lockManager
+	lockStats *lock.StatsManager
 
 	// Worker
 	workerChannel chan func()
@@ -117,6 +121,10 @@ type Runtime struct {
 
 	// secretsManager manages secrets
 	secretsManager *secrets.SecretsManager
+
+	// storeWriterOnce makes sure the version of this process is recorded
+	// as the last writer of the store only once
+	storeWriterOnce sync.Once
 }
 
 // SetXdgDirs ensures the XDG_RUNTIME_DIR env and XDG_CONFIG_HOME variables are set.
@@ -234,13 +242,32 @@ func newRuntimeFromConfig(ctx context.Context, conf *config.Config, options ...R
 	return runtime, nil
 }
 
+// fileLockPath returns the directory of the file locks in tmpDir.
+func fileLockPath(tmpDir string) string {
+	return filepath.Join(tmpDir, "locks")
+}
+
+// shmLockPath returns the name of the shared memory segment of the SHM
+// locks, relative to /dev/shm.
+func shmLockPath() string {
+	if rootless.IsRootless() {
+		return fmt.Sprintf("%s_%d", define.DefaultRootlessSHMLockPath, rootless.GetRootlessUID())
+	}
+	return define.DefaultSHMLockPath
+}
+
+// LockStats returns the counters of the lock acquisitions of this runtime.
+func (r *Runtime) LockStats() lock.Stats {
+	return r.lockStats.Stats()
+}
+
 func getLockManager(runtime *Runtime) (lock.Manager, error) {
 	var err error
 	var manager lock.Manager
 
 	switch runtime.config.Engine.LockType {
 	case "file":
-		lockPath := filepath.Join(runtime.config.Engine.TmpDir, "locks")
+		lockPath := fileLockPath(runtime.config.Engine.TmpDir)
 		manager, err = lock.OpenFileLockManager(lockPath)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
@@ -254,10 +281,7 @@ func getLockManager(runtime *Runtime) (lock.Manager, error) {
 		}
 
 	case "", "shm":
-		lockPath := define.DefaultSHMLockPath
-		if rootless.IsRootless() {
-			lockPath = fmt.Sprintf("%s_%d", define.DefaultRootlessSHMLockPath, rootless.GetRootlessUID())
-		}
+		lockPath := shmLockPath()
 		// Set up the lock manager
 		manager, err = lock.OpenSHMLockManager(lockPath, runtime.config.Engine.NumLocks)
 		if err != nil {
@@ -601,10 +625,12 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (retErr error) {
 		}
 	}
 
-	runtime.lockManager, err = getLockManager(runtime)
+	lockManager, err := getLockManager(runtime)
 	if err != nil {
 		return err
 	}
+	runtime.lockStats = lock.NewStatsManager(lockManager)
+	runtime.lockManager = runtime.lockStats
 
 	// Mark the runtime as valid - ready to be used, cannot be modified
 	// further.
@@ -712,6 +738,9 @@ func (r *Runtime) libimageEvents() {
 			// shutting down.
 			for len(eventChannel) > 0 {
 				libimageEvent := <-eventChannel
+				if storeWritingEvents[libimageEvent.Type] {
+					r.recordStoreWriter()
+				}
 				e := events.Event{
 					ID:     libimageEvent.ID,
 					Name:   libimageEvent.Name,
@@ -890,6 +919,13 @@ func (r *Runtime) Info() (*define.Info, error) {
 	return r.info()
 }
 
+// PerCPUUtilization returns the utilization of each CPU on the host.
+// It samples the CPU times over a short interval, which is why it is not
+// part of Info().
+func (r *Runtime) PerCPUUtilization() ([]define.CPUUsage, error) {
+	return getPerCPUUtilization()
+}
+
 // generateName generates a unique name for a container or pod.
 func (r *Runtime) generateName() (string, error) {
 	for {
@@ -911,6 +947,36 @@ func (r *Runtime) generateName() (string, error) {
 	// The code should never reach here.
 }
 
+// storeVersionFile is the file in the static directory recording the
+// version of Podman that last wrote to the store.
+const storeVersionFile = "podman-version"
+
+// storeWritingEvents are the libimage events that modify the store.
+var storeWritingEvents = map[libimage.EventType]bool{
+	libimage.EventTypeImagePull:   true,
+	libimage.EventTypeImageRemove: true,
+	libimage.EventTypeImageLoad:   true,
+	libimage.EventTypeImageTag:    true,
+	libimage.EventTypeImageUntag:  true,
+}
+
+// recordStoreWriter records the version of this process as the last
+// Podman version to write to the store, so that sharing a store between
+// different versions can be detected.  It only writes the file once per
+// runtime and only if the recorded version differs.
+func (r *Runtime) recordStoreWriter() {
+	r.storeWriterOnce.Do(func() {
+		path := filepath.Join(r.config.Engine.StaticDir, storeVersionFile)
+		current := version.Version.String()
+		if previous, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(previous)) == current {
+			return
+		}
+		if err := ioutils.AtomicWriteFile(path, []byte(current+"\n"), 0o644); err != nil {
+			logrus.Debugf("Recording the Podman version of the store: %v", err)
+		}
+	})
+}
+
 // Configure store and image runtime
 func (r *Runtime) configureStore() error {
 	store, err := storage.GetStore(r.storageConfig)
@@ -13,9 +13,14 @@ import (
 	"github.com/containers/buildah/imagebuildah"
 	"github.com/containers/common/libimage"
 	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/manifest"
 	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/libpod/events"
 	"github.com/containers/podman/v5/pkg/util"
+	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/archive"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
@@ -146,3 +151,144 @@ func DownloadFromFile(reader *os.File) (string, error) {
 
 	return outFile.Name(), nil
 }
+
+// VerifyImageLayers re-reads the extracted layers of the image with the
+// specified ID and checks that their uncompressed contents match the diff
+// IDs recorded when they were committed.  This catches corruption on disk
+// or while decompressing that the digest of the compressed blob does not.
+func (r *Runtime) VerifyImageLayers(ctx context.Context, imageID string) error {
+	img, err := r.store.Image(imageID)
+	if err != nil {
+		return err
+	}
+	for layerID := img.TopLayer; layerID != ""; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		layer, err := r.store.Layer(layerID)
+		if err != nil {
+			return err
+		}
+		if layer.UncompressedDigest == "" {
+			logrus.Debugf("Layer %s of image %s has no diff ID, not verifying it", layer.ID, imageID)
+		} else if err := r.verifyLayer(layer); err != nil {
+			return fmt.Errorf("verifying layer %s of image %s: %w", layer.ID, imageID, err)
+		}
+		layerID = layer.Parent
+	}
+	return nil
+}
+
+// ImageDiffIDs returns the diff IDs of the layers of the image with the
+// specified ID as listed in its config, from the base layer up.
+func (r *Runtime) ImageDiffIDs(ctx context.Context, imageID string) ([]digest.Digest, error) {
+	img, _, err := r.libimageRuntime.LookupImage(imageID, nil)
+	if err != nil {
+		return nil, err
+	}
+	rawManifest, mimeType, err := img.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.FromBlob(rawManifest, manifest.NormalizedMIMEType(mimeType))
+	if err != nil {
+		return nil, err
+	}
+	configDigest := m.ConfigInfo().Digest
+	if configDigest == "" {
+		return nil, fmt.Errorf("image %s has no config listing its layers", imageID)
+	}
+	rawConfig, err := r.store.ImageBigData(imageID, configDigest.String())
+	if err != nil {
+		return nil, err
+	}
+	var config imgspecv1.Image
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		return nil, fmt.Errorf("parsing config of image %s: %w", imageID, err)
+	}
+	return config.RootFS.DiffIDs, nil
+}
+
+// MissingImageLayers returns the diff IDs in the config of the image with
+// the specified ID for which no layer exists in the store, e.g. because they
+// were removed from a partially-corrupted store.
+func (r *Runtime) MissingImageLayers(ctx context.Context, imageID string) ([]digest.Digest, error) {
+	diffIDs, err := r.ImageDiffIDs(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+	var missing []digest.Digest
+	for _, diffID := range diffIDs {
+		if _, err := r.store.LayersByUncompressedDigest(diffID); err != nil {
+			if !errors.Is(err, storage.ErrLayerUnknown) {
+				return nil, err
+			}
+			missing = append(missing, diffID)
+		}
+	}
+	return missing, nil
+}
+
+// verifyLayer compares the digest of the uncompressed diff of the layer to
+// its diff ID.
+func (r *Runtime) verifyLayer(layer *storage.Layer) error {
+	uncompressed := archive.Uncompressed
+	diff, err := r.store.Diff("", layer.ID, &storage.DiffOptions{Compression: &uncompressed})
+	if err != nil {
+		return err
+	}
+	defer diff.Close()
+	digester := layer.UncompressedDigest.Algorithm().Digester()
+	if _, err := io.Copy(digester.Hash(), diff); err != nil {
+		return err
+	}
+	if actual := digester.Digest(); actual != layer.UncompressedDigest {
+		return fmt.Errorf("contents do not match the diff ID: expected %s, got %s", layer.UncompressedDigest, actual)
+	}
+	return nil
+}
+
+// localAnnotationsKey is the big data key of the image annotations that are
+// only recorded locally.
+const localAnnotationsKey = "podman-local-annotations"
+
+// AnnotateImage records annotations of the image with the specified ID in
+// local storage, replacing existing annotations with the same keys.  They
+// are not part of the image and are never pushed.
+func (r *Runtime) AnnotateImage(imageID string, annotations map[string]string) error {
+	merged, err := r.ImageLocalAnnotations(imageID)
+	if err != nil {
+		return err
+	}
+	if merged == nil {
+		merged = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := r.store.SetImageBigData(imageID, localAnnotationsKey, data, nil); err != nil {
+		return fmt.Errorf("annotating image %s: %w", imageID, err)
+	}
+	return nil
+}
+
+// ImageLocalAnnotations returns the annotations recorded with AnnotateImage
+// for the image with the specified ID, or nil if there are none.
+func (r *Runtime) ImageLocalAnnotations(imageID string) (map[string]string, error) {
+	data, err := r.store.ImageBigData(imageID, localAnnotationsKey)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading local annotations of image %s: %w", imageID, err)
+	}
+	var annotations map[string]string
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("parsing local annotations of image %s: %w", imageID, err)
+	}
+	return annotations, nil
+}
@@ -1,21 +1,60 @@
 package libpod
 
 import (
+	"fmt"
 	"net/http"
+	"os"
+	goRuntime "runtime"
 
 	"github.com/containers/podman/v5/libpod"
+	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/api/handlers/utils"
 	api "github.com/containers/podman/v5/pkg/api/types"
+	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/domain/infra/abi"
+	"github.com/gorilla/schema"
 )
 
 func GetInfo(w http.ResponseWriter, r *http.Request) {
+	decoder := r.Context().Value(api.DecoderKey).(*schema.Decoder)
 	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
+
+	query := struct {
+		Detail bool `schema:"detail"`
+	}{}
+	if err := decoder.Decode(&query, r.URL.Query()); err != nil {
+		utils.Error(w, http.StatusBadRequest,
+			fmt.Errorf("failed to parse parameters for %s: %w", r.URL.String(), err))
+		return
+	}
+
 	containerEngine := abi.ContainerEngine{Libpod: runtime}
-	info, err := containerEngine.Info(r.Context())
+	info, err := containerEngine.Info(r.Context(), entities.SystemInfoOptions{Detail: query.Detail})
 	if err != nil {
 		utils.InternalServerError(w, err)
 		return
 	}
+	info.ServiceRuntime = serviceRuntimeInfo()
+	if info.Locks != nil {
+		stats := runtime.LockStats()
+		info.Locks.Acquisitions = &stats.Acquisitions
+		info.Locks.MaxWait = stats.MaxWait
+	}
 	utils.WriteResponse(w, http.StatusOK, info)
 }
+
+// serviceRuntimeInfo returns the resource usage of the service process.
+func serviceRuntimeInfo() *define.ServiceRuntimeInfo {
+	var memStats goRuntime.MemStats
+	goRuntime.ReadMemStats(&memStats)
+	info := &define.ServiceRuntimeInfo{
+		Goroutines: goRuntime.NumGoroutine(),
+		OpenFDs:    -1,
+		HeapAlloc:  memStats.HeapAlloc,
+		HeapSys:    memStats.HeapSys,
+	}
+	if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+		info.OpenFDs = len(entries)
+	}
+	return info
+}
@@ -31,6 +31,12 @@ func (s *APIServer) registerInfoHandlers(r *mux.Router) error {
 	//  - system
 	// summary: Get info
 	// description: Returns information on the system and libpod configuration
+	// parameters:
+	//  - in: query
+	//    name: detail
+	//    type: boolean
+	//    default: false
+	//    description: Include information that is expensive to gather, such as the per-CPU utilization
 	// produces:
 	// - application/json
 	// responses:
@@ -9,12 +9,19 @@ import (
 )
 
 // Info returns information about the libpod environment and its stores
-func Info(ctx context.Context, _ *InfoOptions) (*define.Info, error) {
+func Info(ctx context.Context, options *InfoOptions) (*define.Info, error) {
+	if options == nil {
+		options = new(InfoOptions)
+	}
 	conn, err := bindings.GetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
-	response, err := conn.DoRequest(ctx, nil, http.MethodGet, "/info", nil, nil)
+	params, err := options.ToParams()
+	if err != nil {
+		return nil, err
+	}
+	response, err := conn.DoRequest(ctx, nil, http.MethodGet, "/info", params, nil)
 	if err != nil {
 		return nil, err
 	}
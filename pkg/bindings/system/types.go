@@ -37,6 +37,9 @@ type DiskOptions struct {
 //
 //go:generate go run ../generator/generator.go InfoOptions
 type InfoOptions struct {
+	// Detail also reports information that is expensive to gather,
+	// such as the per-CPU utilization
+	Detail *bool
 }
 
 // CheckOptions are optional options for storage consistency check/repair
@@ -16,3 +16,18 @@ func (o *InfoOptions) Changed(fieldName string) bool {
 func (o *InfoOptions) ToParams() (url.Values, error) {
 	return util.ToParams(o)
 }
+
+// WithDetail set field Detail to given value
+func (o *InfoOptions) WithDetail(value bool) *InfoOptions {
+	o.Detail = &value
+	return o
+}
+
+// GetDetail returns value of field Detail
+func (o *InfoOptions) GetDetail() bool {
+	if o.Detail == nil {
+		var z bool
+		return z
+	}
+	return *o.Detail
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"io"
 	"net/url"
+	"time"
 
 	"github.com/containers/common/pkg/config"
 	"github.com/containers/image/v5/manifest"
@@ -81,6 +82,9 @@ type ImagePullOptions struct {
 	// AllTags can be specified to pull all tags of an image. Note
 	// that this only works if the image does not include a tag.
 	AllTags bool
+	// AllTagsSince restricts AllTags to the tags whose image was created
+	// after it, if set.  Not supported for remote calls.
+	AllTagsSince time.Time
 	// Authfile is the path to the authentication file. Ignored for remote
 	// calls.
 	Authfile string
@@ -116,11 +120,126 @@ type ImagePullOptions struct {
 	// OciDecryptConfig contains the config that can be used to decrypt an image if it is
 	// encrypted if non-nil. If nil, it does not attempt to decrypt an image.
 	OciDecryptConfig *encconfig.DecryptConfig
+	// CollectWarnings records the warnings logged during the pull in the
+	// report, including those of other pulls running in the same process
+	// at the same time.  Not supported for remote calls.
+	CollectWarnings bool
+	// ManifestOnly fetches the manifests of the image into the report
+	// without pulling the image.  Not supported for remote calls.
+	ManifestOnly bool
+	// BlobCache is a directory with a blob cache that is consulted before
+	// fetching blobs from the registry, and that pulled blobs are added
+	// to.  Not supported for remote calls.
+	BlobCache string
+	// ReportReplaced records the ID of the image previously referred to by
+	// the pulled name in the report if the name now refers to a different
+	// image.  Not supported for remote calls.
+	ReportReplaced bool
+	// AcceptSchema1 allows pulling images that only provide a deprecated
+	// Docker schema 1 manifest.  Not supported for remote calls.
+	AcceptSchema1 bool
+	// Progress receives the progress events of the pull if non-nil.
+	// Not supported for remote calls.
+	Progress chan types.ProgressProperties
+	// PrefetchReferrers are the types of referrers ("signature",
+	// "attestation" or "sbom") to pull along with the image.  Not
+	// supported for remote calls.
+	PrefetchReferrers []string
+	// DigestAlgorithm is the digest algorithm ("sha256" or "sha512") to
+	// prefer for the blobs of the image.  Not supported for remote calls.
+	DigestAlgorithm string
+	// MinFreeSpace is the number of bytes that must remain free on the
+	// graph root.  The pull is aborted if less space is free before or
+	// while copying the image.  Not supported for remote calls.
+	MinFreeSpace uint64
+	// ValidateTar re-reads the extracted layers of the pulled images and
+	// fails the pull if they do not match their diff IDs.  Not supported
+	// for remote calls.
+	ValidateTar bool
+	// LocalAnnotations are recorded with the pulled images in local
+	// storage.  Not supported for remote calls.
+	LocalAnnotations map[string]string
+	// MaxLayerRetries is the number of times fetching a single blob is
+	// retried, independent of Retry.  Not supported for remote calls.
+	MaxLayerRetries uint
+	// WaitForRegistry is how long to wait for the registry to become
+	// reachable before pulling.  Not supported for remote calls.
+	WaitForRegistry time.Duration
+	// MirrorOnly only pulls from the mirrors configured in
+	// registries.conf and fails instead of contacting the upstream
+	// registry.  Not supported for remote calls.
+	MirrorOnly bool
+	// TokenCacheDir is a directory to cache registry bearer tokens in
+	// between pulls.  Not supported for remote calls.
+	TokenCacheDir string
+	// RedirectAuthTo is a host to request the bearer tokens from instead
+	// of the host of the realm announced by the registry.  Not supported
+	// for remote calls.
+	RedirectAuthTo string
+	// AcceptGzipOnly pulls the gzip instances of image indexes and fails
+	// if the image has zstd-compressed layers.  Not supported for remote
+	// calls.
+	AcceptGzipOnly bool
+	// ReuseBlobsFrom is a local image whose layers are reused for the
+	// layers of the pulled image with the same diff IDs, even if their
+	// blobs differ.  Not supported for remote calls.
+	ReuseBlobsFrom string
+	// TraceFile is a file to append a trace of the HTTP requests made to
+	// registries to.  Not supported for remote calls.
+	TraceFile string
+	// PreferPlatformVariants are the variants to choose the instance of a
+	// manifest list by, in order of preference, before falling back to
+	// the default choice.  Not supported for remote calls.
+	PreferPlatformVariants []string
+	// CheckpointCompatible warns about pulled images whose containers
+	// cannot be checkpointed and restored with CRIU on this host.  Not
+	// supported for remote calls.
+	CheckpointCompatible bool
+	// StrictPlatform fails the pull if the image does not provide exactly
+	// the requested platform instead of pulling the closest one.  Not
+	// supported for remote calls.
+	StrictPlatform bool
+	// RecordToDB writes a pull-record event with the digest of the image,
+	// the duration and the result of the pull to the event log.  Not
+	// supported for remote calls.
+	RecordToDB bool
+	// NormalizeName reports the fully-qualified reference the pulled name
+	// was resolved to in ImagePullReport.ResolvedName.  Ignored with
+	// AllTags.  Not supported for remote calls.
+	NormalizeName bool
+	// RepairLayers only pulls an image that is present locally if some of
+	// its layers are missing, downloading just those layers, and reports
+	// their number in ImagePullReport.RepairedLayers.  Overrides
+	// PullPolicy and is ignored with AllTags.  Not supported for remote
+	// calls.
+	RepairLayers bool
+	// FIPS fails the pull if a registry is accessed without TLS or with a
+	// TLS version or cipher suite that is not approved for FIPS 140-3, or
+	// if a blob or manifest is referenced by a digest with an algorithm
+	// that is not approved.  Not supported for remote calls.
+	FIPS bool
+	// VerifyLayers are the diff IDs the layers of each pulled image must
+	// have, from the base layer up.  The pull fails if they differ.  Not
+	// supported for remote calls.
+	VerifyLayers []digest.Digest
 }
 
 // ImagePullReport is the response from pulling one or more images.
 type ImagePullReport = entitiesTypes.ImagePullReport
 
+// ImagePullReferrer describes a referrer pulled along with an image.
+type ImagePullReferrer = entitiesTypes.ImagePullReferrer
+
+// ImagePullBlob describes a blob of a pulled image.
+type ImagePullBlob = entitiesTypes.ImagePullBlob
+
+// ImagePullResult is the result of pulling images printed by
+// `podman pull --json`.
+type ImagePullResult = entitiesTypes.ImagePullResult
+
+// ImagePullResultImage describes an image in ImagePullResult.
+type ImagePullResultImage = entitiesTypes.ImagePullResultImage
+
 // ImagePushOptions are the arguments for pushing images.
 type ImagePushOptions struct {
 	// All indicates that all images referenced in a manifest list should be pushed
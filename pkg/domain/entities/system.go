@@ -7,6 +7,7 @@ import (
 // ServiceOptions provides the input for starting an API and sidecar pprof services
 type ServiceOptions = types.ServiceOptions
 type SystemPruneOptions = types.SystemPruneOptions
+type SystemInfoOptions = types.SystemInfoOptions
 type SystemPruneReport = types.SystemPruneReport
 type SystemMigrateOptions = types.SystemMigrateOptions
 type SystemCheckOptions = types.SystemCheckOptions
@@ -48,6 +48,9 @@ func (i *ImageSummary) IsDangling() bool {
 
 type ImageInspectReport struct {
 	*inspect.ImageData
+	// LocalAnnotations were recorded when pulling the image and are not
+	// part of the image
+	LocalAnnotations map[string]string `json:"LocalAnnotations,omitempty"`
 }
 
 type ImageTreeReport struct {
@@ -145,6 +148,82 @@ type ImagePullReport struct {
 	Images []string `json:"images,omitempty"`
 	// ID contains image id (retained for backwards compatibility)
 	ID string `json:"id,omitempty"`
+	// Warnings contains the warnings logged while pulling
+	Warnings []string `json:"warnings,omitempty"`
+	// Manifests contains the raw manifests fetched instead of pulling the
+	// image: the manifest list, if any, followed by the selected manifest
+	Manifests []string `json:"manifests,omitempty"`
+	// Replaced contains the ID of the image the pulled name referred to
+	// before, if the pull moved the name to a different image
+	Replaced string `json:"replaced,omitempty"`
+	// CacheHit is true if the image was already present and nothing was
+	// copied, e.g. with the "missing" or "newer" pull policies
+	CacheHit bool `json:"cacheHit,omitempty"`
+	// Referrers lists the signatures, attestations and SBOMs of the image
+	// that were pulled along with it
+	Referrers []ImagePullReferrer `json:"referrers,omitempty"`
+	// Blobs lists the blobs of the pulled images along with the digest
+	// algorithm used for them.  Only set if a digest algorithm was
+	// requested.
+	Blobs []ImagePullBlob `json:"blobs,omitempty"`
+	// ResolvedName is the fully-qualified reference the pulled name was
+	// resolved to, e.g. quay.io/libpod/alpine:latest for alpine.  Only set
+	// if requested.
+	ResolvedName string `json:"resolvedName,omitempty"`
+	// RepairedLayers is the number of missing layers of a locally present
+	// image that were downloaded again.  Only set if requested.
+	RepairedLayers int `json:"repairedLayers,omitempty"`
+}
+
+// ImagePullReferrer describes a referrer pulled along with an image
+type ImagePullReferrer struct {
+	// Type is "signature", "attestation" or "sbom"
+	Type string `json:"type"`
+	// Name is the tag the referrer was pulled from
+	Name string `json:"name"`
+	// Digest is the manifest digest of the referrer
+	Digest string `json:"digest"`
+}
+
+// ImagePullBlob describes a blob of a pulled image
+type ImagePullBlob struct {
+	// Digest of the blob
+	Digest string `json:"digest"`
+	// Algorithm is the digest algorithm, e.g. "sha256"
+	Algorithm string `json:"algorithm"`
+}
+
+// ImagePullResult is the result of pulling images printed by
+// `podman pull --json`
+type ImagePullResult struct {
+	// Images lists the images pulled for every image given on the
+	// command line, in order.  An image that failed to be pulled is
+	// listed with its error.
+	Images []ImagePullResultImage `json:"images"`
+}
+
+// ImagePullResultImage describes an image in ImagePullResult
+type ImagePullResultImage struct {
+	// Reference is the image as given on the command line
+	Reference string `json:"reference"`
+	// Status is "pulled" or "failed"
+	Status string `json:"status"`
+	// Error is the error of a failed pull
+	Error string `json:"error,omitempty"`
+	// ID of the pulled image
+	ID string `json:"id,omitempty"`
+	// Names are the tags of the pulled image
+	Names []string `json:"names,omitempty"`
+	// Digest of the manifest of the pulled image
+	Digest string `json:"digest,omitempty"`
+	// Size of the pulled image in bytes
+	Size int64 `json:"size,omitempty"`
+	// ResolvedName is the fully-qualified reference Reference was
+	// resolved to.  Only set with --normalize-name.
+	ResolvedName string `json:"resolvedName,omitempty"`
+	// RepairedLayers is the number of missing layers that were downloaded
+	// again.  Only set with --download-only-missing-layers.
+	RepairedLayers int `json:"repairedLayers,omitempty"`
 }
 
 type ImagePushStream struct {
@@ -15,6 +15,11 @@ type ServiceOptions struct {
 	URI         string        // Path to unix domain socket service should listen on
 }
 
+// SystemInfoOptions provides options for gathering system information.
+type SystemInfoOptions struct {
+	Detail bool // also report expensive-to-gather information, e.g. per-CPU usage
+}
+
 // SystemCheckOptions provides options for checking storage consistency.
 type SystemCheckOptions struct {
 	Quick                       bool           // skip the most time-intensive checks
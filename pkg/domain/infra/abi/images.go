@@ -11,8 +11,10 @@ import (
 	"os/user"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/transports"
 	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
 	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/domain/entities/reports"
@@ -242,6 +245,56 @@ func (ir *ImageEngine) Unmount(ctx context.Context, nameOrIDs []string, options
 }
 
 func (ir *ImageEngine) Pull(ctx context.Context, rawImage string, options entities.ImagePullOptions) (*entities.ImagePullReport, error) {
+	if !options.RecordToDB {
+		return ir.pull(ctx, rawImage, options)
+	}
+	start := time.Now()
+	report, err := ir.pull(ctx, rawImage, options)
+	ir.recordPull(rawImage, report, err, time.Since(start))
+	return report, err
+}
+
+func (ir *ImageEngine) pull(ctx context.Context, rawImage string, options entities.ImagePullOptions) (*entities.ImagePullReport, error) {
+	for _, referrerType := range options.PrefetchReferrers {
+		if _, ok := referrerTagSuffixes[referrerType]; !ok {
+			return nil, fmt.Errorf("invalid referrer type %q: must be \"signature\", \"attestation\" or \"sbom\"", referrerType)
+		}
+	}
+	if options.DigestAlgorithm != "" && !slices.Contains(pullDigestAlgorithms, digest.Algorithm(options.DigestAlgorithm)) {
+		return nil, fmt.Errorf("invalid digest algorithm %q: must be \"sha256\" or \"sha512\"", options.DigestAlgorithm)
+	}
+
+	if options.WaitForRegistry > 0 && options.PullPolicy != config.PullPolicyNever {
+		sys := ir.pullSystemContext(options)
+		registries, err := pullRegistries(sys, rawImage)
+		if err != nil {
+			return nil, err
+		}
+		if err := waitForRegistry(ctx, sys, registries, options.WaitForRegistry); err != nil {
+			return nil, err
+		}
+	}
+	if options.ManifestOnly {
+		return ir.pullManifests(ctx, rawImage, options)
+	}
+
+	var damaged *libimage.Image
+	var missingLayers int
+	if options.RepairLayers && !options.AllTags {
+		img, missing, err := ir.missingLayers(ctx, rawImage)
+		if err != nil {
+			return nil, err
+		}
+		// An image with all of its layers is not pulled again, one
+		// with missing layers is, which only downloads the blobs of
+		// the layers that are not in the store.
+		options.PullPolicy = config.PullPolicyMissing
+		if missing > 0 {
+			options.PullPolicy = config.PullPolicyAlways
+			damaged, missingLayers = img, missing
+		}
+	}
+
 	pullOptions := &libimage.PullOptions{AllTags: options.AllTags}
 	pullOptions.AuthFilePath = options.Authfile
 	pullOptions.CertDirPath = options.CertDir
@@ -255,6 +308,7 @@ func (ir *ImageEngine) Pull(ctx context.Context, rawImage string, options entiti
 	pullOptions.Writer = options.Writer
 	pullOptions.OciDecryptConfig = options.OciDecryptConfig
 	pullOptions.MaxRetries = options.Retry
+	pullOptions.Progress = options.Progress
 
 	if options.RetryDelay != "" {
 		duration, err := time.ParseDuration(options.RetryDelay)
@@ -268,7 +322,142 @@ func (ir *ImageEngine) Pull(ctx context.Context, rawImage string, options entiti
 		pullOptions.Writer = os.Stderr
 	}
 
-	pulledImages, err := ir.Libpod.LibimageRuntime().Pull(ctx, rawImage, options.PullPolicy, pullOptions)
+	if options.BlobCache != "" {
+		lookup, err := blobCacheLookup(options.BlobCache)
+		if err != nil {
+			return nil, err
+		}
+		pullOptions.SourceLookupReferenceFunc = lookup
+		pullOptions.DestinationLookupReferenceFunc = lookup
+	}
+	// chain wraps the registry references of the source with wrap, after
+	// the wrappers chained so far.
+	chain := func(wrap func(types.ImageReference) types.ImageReference) {
+		pullOptions.SourceLookupReferenceFunc = chainDockerLookup(pullOptions.SourceLookupReferenceFunc, wrap)
+	}
+	if options.TokenCacheDir != "" {
+		cache, err := newTokenCache(options.TokenCacheDir)
+		if err != nil {
+			return nil, err
+		}
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return tokenCacheReference{ImageReference: ref, cache: cache}
+		})
+	}
+	if options.RedirectAuthTo != "" {
+		if options.TokenCacheDir != "" {
+			return nil, errors.New("tokens requested from a redirected realm cannot be cached")
+		}
+		if err := checkAuthRedirectHost(options.RedirectAuthTo); err != nil {
+			return nil, err
+		}
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return authRedirectReference{ImageReference: ref, host: options.RedirectAuthTo}
+		})
+	}
+	if options.FIPS {
+		if options.SkipTLSVerify == types.OptionalBoolTrue {
+			return nil, errors.New("pulling without TLS verification is not allowed in FIPS mode")
+		}
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return fipsReference{ImageReference: ref}
+		})
+	}
+	if options.MirrorOnly {
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return mirrorOnlyReference{ImageReference: ref}
+		})
+	}
+	if options.StrictPlatform {
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return strictPlatformReference{ImageReference: ref}
+		})
+	}
+	if len(options.PreferPlatformVariants) > 0 {
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return variantPreferenceReference{ImageReference: ref, variants: options.PreferPlatformVariants}
+		})
+	}
+	if options.AcceptGzipOnly {
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return gzipOnlyReference{ImageReference: ref}
+		})
+	}
+	chain(func(ref types.ImageReference) types.ImageReference {
+		return schema1CheckReference{ImageReference: ref, accept: options.AcceptSchema1}
+	})
+	if options.MaxLayerRetries > 0 {
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return blobRetryReference{ImageReference: ref, maxRetries: options.MaxLayerRetries}
+		})
+	}
+	if options.ReuseBlobsFrom != "" {
+		diffIDs, cacheDir, err := ir.reuseBlobsDiffIDs(ctx, options.ReuseBlobsFrom)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(cacheDir)
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return reuseBlobsReference{ImageReference: ref, reuseFrom: options.ReuseBlobsFrom, diffIDs: diffIDs}
+		})
+		pullOptions.BlobInfoCacheDirPath = cacheDir
+	}
+	if options.TraceFile != "" {
+		traceFile, err := os.OpenFile(options.TraceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+		defer traceFile.Close()
+		tracer := &pullTracer{w: traceFile}
+		chain(func(ref types.ImageReference) types.ImageReference {
+			return traceReference{ImageReference: ref, tracer: tracer}
+		})
+	}
+	// libimage only looks up the source when copying, so if it is never
+	// called the pull policy was satisfied by a local image.
+	var copied atomic.Bool
+	pullOptions.SourceLookupReferenceFunc = copyRecorder(&copied, pullOptions.SourceLookupReferenceFunc)
+
+	// The name the image is stored as is only known after the pull, so
+	// remember the images all names it may be stored as refer to.
+	var candidates []reference.Named
+	var previous map[string]string
+	if (options.ReportReplaced || options.NormalizeName) && !options.AllTags {
+		candidates = pullCandidates(ir.pullSystemContext(options), rawImage)
+	}
+	if options.ReportReplaced && !options.AllTags {
+		previous = ir.candidateImages(candidates)
+	}
+
+	var collector *warningCollector
+	if options.CollectWarnings {
+		collector = pullWarnings.collect()
+	}
+	pullCtx := ctx
+	var stopGuard func() error
+	if options.MinFreeSpace > 0 {
+		graphRoot := ir.Libpod.StorageConfig().GraphRoot
+		if err := checkFreeSpace(graphRoot, options.MinFreeSpace); err != nil {
+			return nil, err
+		}
+		pullCtx, pullOptions.Progress, stopGuard = freeSpaceGuard(ctx, graphRoot, options.MinFreeSpace, pullOptions.Progress)
+	}
+	pulledImages, err := ir.pullNames(pullCtx, rawImage, options, pullOptions)
+	if stopGuard != nil {
+		// Report why the pull was canceled rather than the cancellation.
+		if guardErr := stopGuard(); guardErr != nil && err != nil {
+			err = guardErr
+		}
+	}
+	if err == nil && options.CheckpointCompatible {
+		// Checked before the warnings are collected, so that
+		// --fail-on-warning fails on incompatible images.
+		ir.checkCheckpointCompatible(ctx, pulledImages)
+	}
+	var warnings []string
+	if collector != nil {
+		warnings = pullWarnings.stop(collector)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -276,9 +465,80 @@ func (ir *ImageEngine) Pull(ctx context.Context, rawImage string, options entiti
 	pulledIDs := make([]string, len(pulledImages))
 	for i := range pulledImages {
 		pulledIDs[i] = pulledImages[i].ID()
+		if options.ValidateTar {
+			if err := ir.Libpod.VerifyImageLayers(ctx, pulledIDs[i]); err != nil {
+				return nil, err
+			}
+		}
+		if len(options.VerifyLayers) > 0 {
+			diffIDs, err := ir.Libpod.ImageDiffIDs(ctx, pulledIDs[i])
+			if err != nil {
+				return nil, err
+			}
+			if err := checkDiffIDs(diffIDs, options.VerifyLayers); err != nil {
+				return nil, fmt.Errorf("verifying layers of image %s: %w", pulledIDs[i], err)
+			}
+		}
+		if len(options.LocalAnnotations) > 0 {
+			if err := ir.Libpod.AnnotateImage(pulledIDs[i], options.LocalAnnotations); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	return &entities.ImagePullReport{Images: pulledIDs}, nil
+	report := &entities.ImagePullReport{Images: pulledIDs, Warnings: warnings, CacheHit: !copied.Load()}
+	if len(options.PrefetchReferrers) > 0 {
+		if report.Referrers, err = ir.pullReferrers(ctx, pulledImages, options, pullOptions); err != nil {
+			return nil, err
+		}
+	}
+	if options.DigestAlgorithm != "" {
+		if report.Blobs, err = pulledBlobs(ctx, pulledImages, digest.Algorithm(options.DigestAlgorithm)); err != nil {
+			return nil, err
+		}
+	}
+	var name string
+	if len(candidates) > 0 && len(pulledImages) == 1 {
+		name = pulledName(pulledImages[0], candidates)
+	}
+	if id, ok := previous[name]; ok && id != pulledIDs[0] {
+		report.Replaced = id
+	}
+	if options.NormalizeName {
+		report.ResolvedName = name
+	}
+	if damaged != nil && len(pulledIDs) == 1 && damaged.ID() == pulledIDs[0] {
+		missing, err := ir.Libpod.MissingImageLayers(ctx, pulledIDs[0])
+		if err != nil {
+			return nil, err
+		}
+		report.RepairedLayers = missingLayers - len(missing)
+	}
+	return report, nil
+}
+
+// pullNames pulls rawImage, or with AllTagsSince set, each of its tags
+// created after that time.
+func (ir *ImageEngine) pullNames(ctx context.Context, rawImage string, options entities.ImagePullOptions, pullOptions *libimage.PullOptions) ([]*libimage.Image, error) {
+	if !options.AllTags || options.AllTagsSince.IsZero() {
+		return ir.Libpod.LibimageRuntime().Pull(ctx, rawImage, options.PullPolicy, pullOptions)
+	}
+
+	names, err := tagsCreatedSince(ctx, ir.pullSystemContext(options), rawImage, options.AllTagsSince)
+	if err != nil {
+		return nil, err
+	}
+	tagOptions := *pullOptions
+	tagOptions.AllTags = false
+	var pulledImages []*libimage.Image
+	for _, name := range names {
+		images, err := ir.Libpod.LibimageRuntime().Pull(ctx, name, options.PullPolicy, &tagOptions)
+		if err != nil {
+			return nil, err
+		}
+		pulledImages = append(pulledImages, images...)
+	}
+	return pulledImages, nil
 }
 
 func (ir *ImageEngine) Inspect(ctx context.Context, namesOrIDs []string, opts entities.InspectOptions) ([]*entities.ImageInspectReport, []error, error) {
@@ -302,6 +562,9 @@ func (ir *ImageEngine) Inspect(ctx context.Context, namesOrIDs []string, opts en
 		if err := domainUtils.DeepCopy(&report, result); err != nil {
 			return nil, nil, err
 		}
+		if report.LocalAnnotations, err = ir.Libpod.ImageLocalAnnotations(img.ID()); err != nil {
+			return nil, nil, err
+		}
 		reports = append(reports, &report)
 	}
 	return reports, errs, nil
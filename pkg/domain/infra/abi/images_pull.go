@@ -0,0 +1,1289 @@
+package abi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/containers/buildah/pkg/parse"
+	"github.com/containers/common/libimage"
+	"github.com/containers/common/pkg/config"
+	"github.com/containers/common/pkg/retry"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/pkg/blobcache"
+	"github.com/containers/image/v5/pkg/blobinfocache"
+	"github.com/containers/image/v5/pkg/shortnames"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/transports"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/domain/entities"
+	"github.com/containers/storage"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// pullWarnings is installed once as a logrus hook and fans out warnings to
+// all pulls currently collecting them.
+var pullWarnings = &warningHook{collectors: map[*warningCollector]struct{}{}}
+
+type warningHook struct {
+	once       sync.Once
+	lock       sync.Mutex
+	collectors map[*warningCollector]struct{}
+}
+
+// warningCollector records the warnings logged while it is registered.
+type warningCollector struct {
+	lock     sync.Mutex
+	warnings []string
+}
+
+func (h *warningHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel}
+}
+
+func (h *warningHook) Fire(entry *logrus.Entry) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for c := range h.collectors {
+		c.lock.Lock()
+		c.warnings = append(c.warnings, entry.Message)
+		c.lock.Unlock()
+	}
+	return nil
+}
+
+// collect starts recording warnings.  Note that warnings of concurrent pulls
+// in the same process cannot be told apart and are recorded by all of them.
+// Warnings are only seen if the log level includes them.
+func (h *warningHook) collect() *warningCollector {
+	h.once.Do(func() {
+		logrus.AddHook(h)
+	})
+	c := &warningCollector{}
+	h.lock.Lock()
+	h.collectors[c] = struct{}{}
+	h.lock.Unlock()
+	return c
+}
+
+// stop unregisters the collector and returns the warnings it recorded.
+func (h *warningHook) stop(c *warningCollector) []string {
+	h.lock.Lock()
+	delete(h.collectors, c)
+	h.lock.Unlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.warnings
+}
+
+// pullManifests fetches the manifest of rawImage without pulling the image.
+// If it is a manifest list, the instance matching the requested platform is
+// fetched as well.
+func (ir *ImageEngine) pullManifests(ctx context.Context, rawImage string, options entities.ImagePullOptions) (*entities.ImagePullReport, error) {
+	sys := ir.pullSystemContext(options)
+
+	var refs []types.ImageReference
+	if ref, err := alltransports.ParseImageName(rawImage); err == nil {
+		refs = append(refs, ref)
+	} else {
+		resolved, err := shortnames.Resolve(sys, rawImage)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range resolved.PullCandidates {
+			ref, err := alltransports.ParseImageName("docker://" + candidate.Value.String())
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, ref)
+		}
+	}
+
+	var latestErr error
+	for _, ref := range refs {
+		manifests, err := fetchManifests(ctx, sys, ref)
+		if err != nil {
+			if latestErr == nil {
+				latestErr = err
+			} else {
+				latestErr = fmt.Errorf("tried %v\n: %w", err, latestErr)
+			}
+			continue
+		}
+		return &entities.ImagePullReport{Manifests: manifests}, nil
+	}
+	return nil, latestErr
+}
+
+// pullSystemContext returns the system context of the runtime with the
+// registry and platform settings of options applied, for accessing
+// registries outside of libimage.
+func (ir *ImageEngine) pullSystemContext(options entities.ImagePullOptions) *types.SystemContext {
+	sys := ir.Libpod.SystemContext()
+	if options.Authfile != "" {
+		sys.AuthFilePath = options.Authfile
+	}
+	if options.CertDir != "" {
+		sys.DockerCertPath = options.CertDir
+	}
+	if options.Username != "" {
+		sys.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: options.Username,
+			Password: options.Password,
+		}
+	}
+	sys.DockerInsecureSkipTLSVerify = options.SkipTLSVerify
+	if options.SkipTLSVerify == types.OptionalBoolTrue {
+		sys.OCIInsecureSkipTLSVerify = true
+	}
+	if options.Arch != "" {
+		sys.ArchitectureChoice = options.Arch
+	}
+	if options.OS != "" {
+		sys.OSChoice = options.OS
+	}
+	if options.Variant != "" {
+		sys.VariantChoice = options.Variant
+	}
+	return sys
+}
+
+// tagsCreatedSince returns the names of all tags in the repository of
+// rawImage whose image was created after since.  Registries do not expose
+// when a tag was pushed, so the creation time in the image configuration is
+// used instead.  Tags whose creation time cannot be determined are included.
+func tagsCreatedSince(ctx context.Context, sys *types.SystemContext, rawImage string, since time.Time) ([]string, error) {
+	ref, err := alltransports.ParseImageName("docker://" + strings.TrimPrefix(rawImage, "docker://"))
+	if err != nil {
+		return nil, err
+	}
+	named := ref.DockerReference()
+	if !reference.IsNameOnly(named) {
+		return nil, fmt.Errorf("%s: tag or digest can not be specified with --all-tags", rawImage)
+	}
+	tags, err := docker.GetRepositoryTags(ctx, sys, ref)
+	if err != nil {
+		return nil, fmt.Errorf("getting repository tags: %w", err)
+	}
+
+	var names []string
+	for _, tag := range tags {
+		tagged, err := reference.WithTag(named, tag)
+		if err != nil {
+			return nil, fmt.Errorf("creating tagged reference: %w", err)
+		}
+		created, err := imageCreated(ctx, sys, tagged)
+		if err != nil {
+			logrus.Warnf("Unable to determine when %s was created, pulling it: %v", tagged, err)
+		} else if !created.After(since) {
+			logrus.Debugf("Skipping %s created at %s", tagged, created)
+			continue
+		}
+		names = append(names, tagged.String())
+	}
+	return names, nil
+}
+
+// imageCreated returns the creation time of the image tagged in a registry.
+func imageCreated(ctx context.Context, sys *types.SystemContext, tagged reference.NamedTagged) (time.Time, error) {
+	ref, err := docker.NewReference(tagged)
+	if err != nil {
+		return time.Time{}, err
+	}
+	img, err := ref.NewImage(ctx, sys)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer img.Close()
+	info, err := img.Inspect(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if info.Created == nil {
+		return time.Time{}, errors.New("image has no creation time")
+	}
+	return *info.Created, nil
+}
+
+// fetchManifests returns the manifest of ref and, for manifest lists, the
+// manifest of the instance matching the platform in sys.
+func fetchManifests(ctx context.Context, sys *types.SystemContext, ref types.ImageReference) ([]string, error) {
+	src, err := ref.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, fmt.Errorf("reading image %q: %w", transports.ImageName(ref), err)
+	}
+	defer src.Close()
+
+	rawManifest, manifestType, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest %q: %w", transports.ImageName(ref), err)
+	}
+	manifests := []string{string(rawManifest)}
+	if !manifest.MIMETypeIsMultiImage(manifestType) {
+		return manifests, nil
+	}
+
+	list, err := manifest.ListFromBlob(rawManifest, manifestType)
+	if err != nil {
+		return nil, fmt.Errorf("parsing manifest list %q: %w", transports.ImageName(ref), err)
+	}
+	instance, err := list.ChooseInstance(sys)
+	if err != nil {
+		return nil, fmt.Errorf("choosing image instance of %q: %w", transports.ImageName(ref), err)
+	}
+	rawInstance, _, err := src.GetManifest(ctx, &instance)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest %s of %q: %w", instance, transports.ImageName(ref), err)
+	}
+	return append(manifests, string(rawInstance)), nil
+}
+
+// blobCacheLookup returns a lookup function which wraps the source and the
+// destination of a copy in a blob cache stored in dir.  Blobs are read from
+// the cache if present and added to it otherwise.  The cache writes every
+// blob to a temporary file that is renamed into place, so the directory can
+// safely be shared by concurrent pulls, including from other processes.
+func blobCacheLookup(dir string) (libimage.LookupReferenceFunc, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating blob cache directory: %w", err)
+	}
+	return func(ref types.ImageReference) (types.ImageReference, error) {
+		return blobcache.NewBlobCache(ref, dir, types.PreserveOriginal)
+	}, nil
+}
+
+// schema1CheckReference is an image reference whose image source checks the
+// type of the manifest before anything is copied.
+type schema1CheckReference struct {
+	types.ImageReference
+	accept bool
+}
+
+// NewImageSource returns the image source of the wrapped reference, or an
+// error if the image has a Docker schema 1 manifest that is not accepted.
+func (r schema1CheckReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	// The docker transport caches the manifest, so this does not cause
+	// an additional request.  Errors are left to the copy to report.
+	_, manifestType, err := src.GetManifest(ctx, nil)
+	if err != nil || (manifestType != manifest.DockerV2Schema1MediaType && manifestType != manifest.DockerV2Schema1SignedMediaType) {
+		return src, nil
+	}
+	name := transports.ImageName(r.ImageReference)
+	if !r.accept {
+		src.Close()
+		return nil, fmt.Errorf("%s only provides a deprecated Docker schema 1 manifest, use --accept-schema1 to pull it anyway", name)
+	}
+	logrus.Warnf("Pulling %s which only provides a deprecated Docker schema 1 manifest", name)
+	return src, nil
+}
+
+// gzipOnlyReference is an image reference whose image source prefers the
+// gzip instances of image indexes and rejects zstd-compressed layers.
+type gzipOnlyReference struct {
+	types.ImageReference
+}
+
+// NewImageSource returns the image source of the wrapped reference, reading
+// the gzip instance of an image index, or an error naming the first
+// zstd-compressed layer of the image.
+func (r gzipOnlyReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	// Errors are left to the copy to report.
+	rawManifest, manifestType, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return src, nil
+	}
+	name := transports.ImageName(r.ImageReference)
+	var instance digest.Digest
+	if manifest.MIMETypeIsMultiImage(manifestType) {
+		if instance, err = chooseGzipInstance(sys, rawManifest, manifestType); err != nil {
+			src.Close()
+			return nil, err
+		}
+		if rawManifest, manifestType, err = src.GetManifest(ctx, &instance); err != nil {
+			src.Close()
+			return nil, err
+		}
+	}
+	if err := checkGzipLayers(name, rawManifest, manifestType); err != nil {
+		src.Close()
+		return nil, err
+	}
+	if instance == "" {
+		return src, nil
+	}
+	logrus.Debugf("Pulling gzip instance %s of %s", instance, name)
+	return &instanceSource{ImageSource: src, instance: instance}, nil
+}
+
+// chooseGzipInstance returns the digest of the instance of the image index
+// or manifest list rawManifest to pull, preferring instances which are not
+// marked as zstd-compressed.  Unlike containers/image, it falls back to a
+// zstd instance only if there is no other one for the platform.
+func chooseGzipInstance(sys *types.SystemContext, rawManifest []byte, manifestType string) (digest.Digest, error) {
+	list, err := manifest.ListFromBlob(rawManifest, manifestType)
+	if err != nil {
+		return "", err
+	}
+	if manifestType == imgspecv1.MediaTypeImageIndex {
+		var index imgspecv1.Index
+		if err := json.Unmarshal(rawManifest, &index); err != nil {
+			return "", err
+		}
+		var gzipInstances []imgspecv1.Descriptor
+		for _, instance := range index.Manifests {
+			if instance.Annotations[zstdInstanceAnnotation] != "true" {
+				gzipInstances = append(gzipInstances, instance)
+			}
+		}
+		if len(gzipInstances) > 0 {
+			if instance, err := manifest.OCI1IndexFromComponents(gzipInstances, nil).ChooseInstance(sys); err == nil {
+				return instance, nil
+			}
+		}
+	}
+	return list.ChooseInstance(sys)
+}
+
+// zstdInstanceAnnotation marks the zstd-compressed instances containers/image
+// adds to image indexes next to the gzip ones.
+const zstdInstanceAnnotation = "io.github.containers.compression.zstd"
+
+// checkGzipLayers returns an error naming the first zstd-compressed layer of
+// the image name with the manifest rawManifest.
+func checkGzipLayers(name string, rawManifest []byte, manifestType string) error {
+	m, err := manifest.FromBlob(rawManifest, manifestType)
+	if err != nil {
+		// Left to the copy to report.
+		return nil
+	}
+	for _, layer := range m.LayerInfos() {
+		if strings.HasSuffix(layer.MediaType, "+zstd") {
+			return fmt.Errorf("layer %s of %s is zstd-compressed, but only gzip-compressed layers are accepted with --accept-gzip-only", layer.Digest, name)
+		}
+	}
+	return nil
+}
+
+// instanceSource is an image source reading an image index as its
+// chosen instance, so that containers/image cannot choose another one.
+type instanceSource struct {
+	types.ImageSource
+	instance digest.Digest
+}
+
+// Reference returns the reference of the wrapped source.  If it includes
+// the digest of the image index, it is replaced with the one of the
+// instance, which the manifest read is verified against.
+func (s *instanceSource) Reference() types.ImageReference {
+	ref := s.ImageSource.Reference()
+	if _, ok := ref.DockerReference().(reference.Canonical); !ok {
+		return ref
+	}
+	named, err := reference.WithDigest(reference.TrimNamed(ref.DockerReference()), s.instance)
+	if err != nil {
+		return ref
+	}
+	instanceRef, err := docker.NewReference(named)
+	if err != nil {
+		return ref
+	}
+	return instanceRef
+}
+
+func (s *instanceSource) GetManifest(ctx context.Context, instanceDigest *digest.Digest) ([]byte, string, error) {
+	if instanceDigest == nil {
+		instanceDigest = &s.instance
+	}
+	return s.ImageSource.GetManifest(ctx, instanceDigest)
+}
+
+func (s *instanceSource) GetSignatures(ctx context.Context, instanceDigest *digest.Digest) ([][]byte, error) {
+	if instanceDigest == nil {
+		instanceDigest = &s.instance
+	}
+	return s.ImageSource.GetSignatures(ctx, instanceDigest)
+}
+
+// variantPreferenceReference is an image reference whose image source
+// reads manifest lists as the instance with the most preferred variant.
+type variantPreferenceReference struct {
+	types.ImageReference
+	variants []string
+}
+
+// NewImageSource returns the image source of the wrapped reference, reading
+// a manifest list as the instance with the most preferred variant, or as
+// the one containers/image would choose if none of them is available.
+func (r variantPreferenceReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	// Errors are left to the copy to report.
+	rawManifest, manifestType, err := src.GetManifest(ctx, nil)
+	if err != nil || !manifest.MIMETypeIsMultiImage(manifestType) {
+		return src, nil
+	}
+	instance, variant, err := chooseVariantInstance(sys, rawManifest, manifestType, r.variants)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	name := transports.ImageName(r.ImageReference)
+	if variant == "" {
+		logrus.Debugf("None of the preferred variants %v is available for %s, pulling instance %s", r.variants, name, instance)
+	} else {
+		logrus.Debugf("Pulling instance %s with variant %s of %s", instance, variant, name)
+	}
+	return &instanceSource{ImageSource: src, instance: instance}, nil
+}
+
+// chooseVariantInstance returns the digest of the first instance of the
+// manifest list rawManifest for the platform of sys with the first of
+// variants that any instance has, and that variant.  If there is none, it
+// returns the instance containers/image chooses and an empty variant.
+func chooseVariantInstance(sys *types.SystemContext, rawManifest []byte, manifestType string, variants []string) (digest.Digest, string, error) {
+	list, err := manifest.ListFromBlob(rawManifest, manifestType)
+	if err != nil {
+		return "", "", err
+	}
+	wantedOS, wantedArch := runtime.GOOS, runtime.GOARCH
+	if sys != nil && sys.OSChoice != "" {
+		wantedOS = sys.OSChoice
+	}
+	if sys != nil && sys.ArchitectureChoice != "" {
+		wantedArch = sys.ArchitectureChoice
+	}
+	for _, variant := range variants {
+		for _, d := range list.Instances() {
+			instance, err := list.Instance(d)
+			if err != nil {
+				return "", "", err
+			}
+			platform := instance.ReadOnly.Platform
+			if platform != nil && platform.OS == wantedOS && platform.Architecture == wantedArch && platform.Variant == variant {
+				return d, variant, nil
+			}
+		}
+	}
+	instance, err := list.ChooseInstance(sys)
+	return instance, "", err
+}
+
+// strictPlatformReference is an image reference whose image source fails
+// if the image does not provide exactly the requested platform.
+type strictPlatformReference struct {
+	types.ImageReference
+}
+
+// NewImageSource returns the image source of the wrapped reference, or an
+// error listing the platforms the image provides if none of them is the
+// requested one.  The instance of a manifest list is still chosen by
+// containers/image, which picks the exact match if there is one.
+func (r strictPlatformReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	// Errors are left to the copy to report.
+	rawManifest, manifestType, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return src, nil
+	}
+	var provided []imgspecv1.Platform
+	if manifest.MIMETypeIsMultiImage(manifestType) {
+		if provided, err = listPlatforms(rawManifest, manifestType); err != nil {
+			return src, nil
+		}
+	} else {
+		img, err := image.FromUnparsedImage(ctx, sys, image.UnparsedInstance(src, nil))
+		if err != nil {
+			return src, nil
+		}
+		config, err := img.OCIConfig(ctx)
+		if err != nil {
+			return src, nil
+		}
+		provided = append(provided, config.Platform)
+	}
+	if err := checkStrictPlatform(transports.ImageName(r.ImageReference), requestedPlatform(sys), provided); err != nil {
+		src.Close()
+		return nil, err
+	}
+	return src, nil
+}
+
+// requestedPlatform returns the platform requested in sys, defaulting to
+// the one of the host.  The variant is only set if it was requested.
+func requestedPlatform(sys *types.SystemContext) imgspecv1.Platform {
+	platform := imgspecv1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	if sys != nil {
+		if sys.OSChoice != "" {
+			platform.OS = sys.OSChoice
+		}
+		if sys.ArchitectureChoice != "" {
+			platform.Architecture = sys.ArchitectureChoice
+		}
+		platform.Variant = sys.VariantChoice
+	}
+	return platform
+}
+
+// listPlatforms returns the platforms of the instances of the manifest
+// list rawManifest, without those of attestations and other artifacts.
+func listPlatforms(rawManifest []byte, manifestType string) ([]imgspecv1.Platform, error) {
+	list, err := manifest.ListFromBlob(rawManifest, manifestType)
+	if err != nil {
+		return nil, err
+	}
+	var platforms []imgspecv1.Platform
+	for _, d := range list.Instances() {
+		instance, err := list.Instance(d)
+		if err != nil {
+			return nil, err
+		}
+		if platform := instance.ReadOnly.Platform; platform != nil && platform.OS != "unknown" {
+			platforms = append(platforms, *platform)
+		}
+	}
+	return platforms, nil
+}
+
+// checkStrictPlatform returns an error listing provided if none of them
+// has the OS and architecture of requested and, if requested has one, its
+// variant.
+func checkStrictPlatform(name string, requested imgspecv1.Platform, provided []imgspecv1.Platform) error {
+	var offered []string
+	for _, platform := range provided {
+		if platform.OS == requested.OS && platform.Architecture == requested.Architecture &&
+			(requested.Variant == "" || platform.Variant == requested.Variant) {
+			return nil
+		}
+		if s := platformString(platform); !slices.Contains(offered, s) {
+			offered = append(offered, s)
+		}
+	}
+	if len(offered) == 0 {
+		return fmt.Errorf("%s does not provide the platform %s", name, platformString(requested))
+	}
+	return fmt.Errorf("%s does not provide the platform %s, only %s", name, platformString(requested), strings.Join(offered, ", "))
+}
+
+// mirrorOnlyReference is an image reference whose image source is read
+// from the first mirror of the registry serving the image.
+type mirrorOnlyReference struct {
+	types.ImageReference
+}
+
+// NewImageSource returns the image source of the first mirror that can be
+// accessed, or an error naming the upstream registry if none can.
+func (r mirrorOnlyReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	named := r.DockerReference()
+	registry, err := sysregistriesv2.FindRegistry(sys, named.Name())
+	if err != nil {
+		return nil, fmt.Errorf("loading registries configuration: %w", err)
+	}
+	upstream := reference.Domain(named)
+	var sources []sysregistriesv2.PullSource
+	if registry != nil {
+		upstream = registry.Location
+		if sources, err = registry.PullSourcesFromReference(named); err != nil {
+			return nil, err
+		}
+		// The upstream location is always the last source.
+		sources = sources[:len(sources)-1]
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no mirror is configured for %s, pulling it would contact the upstream registry %s", named, upstream)
+	}
+
+	var errs []error
+	for _, source := range sources {
+		mirrorRef, err := docker.NewReference(source.Reference)
+		if err != nil {
+			return nil, err
+		}
+		var mirrorSys types.SystemContext
+		if sys != nil {
+			mirrorSys = *sys
+		}
+		if source.Endpoint.Insecure {
+			mirrorSys.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+		}
+		// Like c/image, do not send the credentials of the upstream
+		// registry to mirrors.
+		mirrorSys.DockerAuthConfig = nil
+		mirrorSys.DockerBearerRegistryToken = ""
+		logrus.Debugf("Trying to access mirror %q", source.Reference)
+		src, err := mirrorRef.NewImageSource(ctx, &mirrorSys)
+		if err == nil {
+			return src, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", source.Reference, err))
+	}
+	return nil, fmt.Errorf("no mirror serves %s, not falling back to the upstream registry %s: %w", named, upstream, errors.Join(errs...))
+}
+
+// reuseBlobsDiffIDs returns the diff IDs of the layers of the local image
+// reuseFrom, for a reuseBlobsReference to reuse the layers the pulled image
+// shares with it, even if their blobs were compressed differently.  Layers
+// are shared if they and all layers below them have the same diff ID.
+//
+// The layers are matched by recording the pulled blobs as compressed
+// versions of the local layers in a blob info cache only used by this pull,
+// created in the returned temporary directory that the caller must remove.
+func (ir *ImageEngine) reuseBlobsDiffIDs(ctx context.Context, reuseFrom string) ([]digest.Digest, string, error) {
+	img, _, err := ir.Libpod.LibimageRuntime().LookupImage(reuseFrom, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("looking up image to reuse blobs from: %w", err)
+	}
+	data, err := img.Inspect(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("inspecting image %s to reuse blobs from: %w", reuseFrom, err)
+	}
+	if data.RootFS == nil {
+		return nil, "", fmt.Errorf("image %s to reuse blobs from has no layers", reuseFrom)
+	}
+	cacheDir, err := os.MkdirTemp("", "podman-reuse-blobs-")
+	if err != nil {
+		return nil, "", err
+	}
+	return data.RootFS.Layers, cacheDir, nil
+}
+
+// reuseBlobsReference is an image reference whose image source records the
+// layers shared with a local image in the blob info cache of the pull.
+type reuseBlobsReference struct {
+	types.ImageReference
+	reuseFrom string
+	diffIDs   []digest.Digest
+}
+
+// NewImageSource returns the image source of the wrapped reference.  Failing
+// to match the layers only means that all of them are pulled.
+func (r reuseBlobsReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	img, err := image.FromUnparsedImage(ctx, sys, image.UnparsedInstance(src, nil))
+	if err != nil {
+		logrus.Warnf("Not reusing the layers of %s: %v", r.reuseFrom, err)
+		return src, nil
+	}
+	config, err := img.OCIConfig(ctx)
+	if err != nil {
+		logrus.Warnf("Not reusing the layers of %s: %v", r.reuseFrom, err)
+		return src, nil
+	}
+	shared, err := sharedLayers(img.LayerInfos(), config.RootFS.DiffIDs, r.diffIDs)
+	if err != nil {
+		logrus.Warnf("Not reusing the layers of %s: %v", r.reuseFrom, err)
+		return src, nil
+	}
+	cache := blobinfocache.DefaultCache(sys)
+	for blob, diffID := range shared {
+		cache.RecordDigestUncompressedPair(blob, diffID)
+	}
+	logrus.Debugf("Reusing %d layers of %s", len(shared), r.reuseFrom)
+	return src, nil
+}
+
+// sharedLayers maps the blobs of the layers of an image to their diff IDs,
+// for the layers at the bottom of the image whose diff IDs match reuseDiffIDs.
+func sharedLayers(layers []types.BlobInfo, diffIDs, reuseDiffIDs []digest.Digest) (map[digest.Digest]digest.Digest, error) {
+	if len(layers) != len(diffIDs) {
+		return nil, fmt.Errorf("image has %d layers but %d diff IDs", len(layers), len(diffIDs))
+	}
+	shared := make(map[digest.Digest]digest.Digest)
+	for i, layer := range layers {
+		if i >= len(reuseDiffIDs) || diffIDs[i] != reuseDiffIDs[i] {
+			break
+		}
+		shared[layer.Digest] = diffIDs[i]
+	}
+	return shared, nil
+}
+
+// chainDockerLookup returns a lookup function which wraps the registry
+// references returned by next, if set, with wrap.  References of other
+// transports are returned unchanged.
+func chainDockerLookup(next libimage.LookupReferenceFunc, wrap func(types.ImageReference) types.ImageReference) libimage.LookupReferenceFunc {
+	return func(ref types.ImageReference) (types.ImageReference, error) {
+		if next != nil {
+			var err error
+			if ref, err = next(ref); err != nil {
+				return nil, err
+			}
+		}
+		if ref.Transport().Name() != docker.Transport.Name() {
+			return ref, nil
+		}
+		return wrap(ref), nil
+	}
+}
+
+// copyRecorder returns a lookup function which records in copied that an
+// image is being copied before calling next.
+func copyRecorder(copied *atomic.Bool, next libimage.LookupReferenceFunc) libimage.LookupReferenceFunc {
+	return func(ref types.ImageReference) (types.ImageReference, error) {
+		copied.Store(true)
+		return next(ref)
+	}
+}
+
+// referrerTagSuffixes maps the referrer types to the suffix of the tags
+// cosign stores them under.
+var referrerTagSuffixes = map[string]string{
+	"signature":   "sig",
+	"attestation": "att",
+	"sbom":        "sbom",
+}
+
+// pullReferrers pulls the referrers of the requested types of the pulled
+// images.  Referrers are found using the tag scheme of cosign, e.g. the
+// signatures of sha256:abc are tagged sha256-abc.sig in the repository of
+// the image, as the OCI referrers API is not supported by containers/image.
+func (ir *ImageEngine) pullReferrers(ctx context.Context, images []*libimage.Image, options entities.ImagePullOptions, pullOptions *libimage.PullOptions) ([]entities.ImagePullReferrer, error) {
+	sys := ir.pullSystemContext(options)
+	referrerOptions := *pullOptions
+	referrerOptions.AllTags = false
+
+	var referrers []entities.ImagePullReferrer
+	seen := map[string]bool{}
+	for _, img := range images {
+		for _, name := range img.Names() {
+			named, err := reference.ParseNormalizedNamed(name)
+			if err != nil {
+				continue
+			}
+			repo := reference.TrimNamed(named)
+			// The digest of a manifest list is signed, not the
+			// digest of the instance.
+			for _, dgst := range img.Digests() {
+				for _, referrerType := range options.PrefetchReferrers {
+					tag := strings.Replace(dgst.String(), ":", "-", 1) + "." + referrerTagSuffixes[referrerType]
+					tagged, err := reference.WithTag(repo, tag)
+					if err != nil || seen[tagged.String()] {
+						continue
+					}
+					seen[tagged.String()] = true
+					if !tagExists(ctx, sys, tagged) {
+						continue
+					}
+					pulled, err := ir.Libpod.LibimageRuntime().Pull(ctx, tagged.String(), config.PullPolicyAlways, &referrerOptions)
+					if err != nil {
+						return nil, fmt.Errorf("pulling %s of %s: %w", referrerType, repo, err)
+					}
+					for _, referrer := range pulled {
+						referrers = append(referrers, entities.ImagePullReferrer{
+							Type:   referrerType,
+							Name:   tagged.String(),
+							Digest: referrer.Digest().String(),
+						})
+					}
+				}
+			}
+		}
+	}
+	return referrers, nil
+}
+
+// tagExists returns whether the tag exists in the registry.
+func tagExists(ctx context.Context, sys *types.SystemContext, tagged reference.NamedTagged) bool {
+	ref, err := docker.NewReference(tagged)
+	if err != nil {
+		return false
+	}
+	src, err := ref.NewImageSource(ctx, sys)
+	if err != nil {
+		logrus.Debugf("Looking up %s: %v", tagged, err)
+		return false
+	}
+	defer src.Close()
+	if _, _, err := src.GetManifest(ctx, nil); err != nil {
+		logrus.Debugf("Looking up %s: %v", tagged, err)
+		return false
+	}
+	return true
+}
+
+// pullDigestAlgorithms are the digest algorithms that can be requested when
+// pulling.
+var pullDigestAlgorithms = []digest.Algorithm{digest.SHA256, digest.SHA512}
+
+// pulledBlobs lists the config and layer blobs of the pulled images with
+// their digest algorithm.  Blobs are fetched by the digests the manifest
+// refers to them with, so there is nothing to negotiate with the registry:
+// if the manifest does not use the preferred algorithm, a warning is logged
+// and the blob is reported with the algorithm that was used.
+func pulledBlobs(ctx context.Context, images []*libimage.Image, preferred digest.Algorithm) ([]entities.ImagePullBlob, error) {
+	var blobs []entities.ImagePullBlob
+	for _, img := range images {
+		rawManifest, mimeType, err := img.Manifest(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading manifest of image %s: %w", img.ID(), err)
+		}
+		m, err := manifest.FromBlob(rawManifest, mimeType)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest of image %s: %w", img.ID(), err)
+		}
+		infos := []types.BlobInfo{m.ConfigInfo()}
+		for _, layer := range m.LayerInfos() {
+			infos = append(infos, layer.BlobInfo)
+		}
+		fallback := map[digest.Algorithm]bool{}
+		for _, info := range infos {
+			if info.Digest == "" {
+				// schema1 manifests have no config blob
+				continue
+			}
+			algorithm := info.Digest.Algorithm()
+			if algorithm != preferred {
+				fallback[algorithm] = true
+			}
+			blobs = append(blobs, entities.ImagePullBlob{
+				Digest:    info.Digest.String(),
+				Algorithm: algorithm.String(),
+			})
+		}
+		for algorithm := range fallback {
+			logrus.Warnf("Image %s does not provide %s digests, falling back to %s", img.ID(), preferred, algorithm)
+		}
+	}
+	return blobs, nil
+}
+
+// checkDiffIDs returns an error describing the first difference between
+// the diff IDs of an image and the expected ones.
+func checkDiffIDs(diffIDs, expected []digest.Digest) error {
+	for i := 0; i < min(len(diffIDs), len(expected)); i++ {
+		if diffIDs[i] != expected[i] {
+			return fmt.Errorf("layer %d has diff ID %s, expected %s", i+1, diffIDs[i], expected[i])
+		}
+	}
+	if len(diffIDs) != len(expected) {
+		return fmt.Errorf("image has %d layers, expected %d", len(diffIDs), len(expected))
+	}
+	return nil
+}
+
+// missingLayers returns the local image rawImage refers to, if any, along
+// with the number of its layers that are missing from the store.
+func (ir *ImageEngine) missingLayers(ctx context.Context, rawImage string) (*libimage.Image, int, error) {
+	img, _, err := ir.Libpod.LibimageRuntime().LookupImage(strings.TrimPrefix(rawImage, docker.Transport.Name()+"://"), nil)
+	if err != nil {
+		if errors.Is(err, storage.ErrImageUnknown) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	missing, err := ir.Libpod.MissingImageLayers(ctx, img.ID())
+	if err != nil {
+		return nil, 0, fmt.Errorf("checking the layers of %s: %w", rawImage, err)
+	}
+	return img, len(missing), nil
+}
+
+// pullCandidates returns the fully-qualified names the registry reference
+// rawImage can be pulled and stored as: the short-name alias and the
+// unqualified-search registries for short names, the normalized name
+// otherwise.  Other transports have none.  Unlike short-name resolution
+// for pulling, it never prompts.
+func pullCandidates(sys *types.SystemContext, rawImage string) []reference.Named {
+	name := strings.TrimPrefix(rawImage, docker.Transport.Name()+"://")
+	if _, err := alltransports.ParseImageName(name); err == nil {
+		// Not a registry reference.
+		return nil
+	}
+	candidates, err := shortnames.ResolveLocally(sys, name)
+	if err != nil {
+		logrus.Debugf("Resolving %s: %v", name, err)
+		return nil
+	}
+	return candidates
+}
+
+// pulledName returns the first of candidates img was stored as, i.e. the
+// name it was pulled as, or an empty string.  Candidates with a digest
+// are matched against the repo digests of img, as images pulled by digest
+// are not tagged.
+func pulledName(img *libimage.Image, candidates []reference.Named) string {
+	var repoDigests []string
+	for _, candidate := range candidates {
+		digested, ok := candidate.(reference.Digested)
+		if !ok {
+			if slices.Contains(img.Names(), candidate.String()) {
+				return candidate.String()
+			}
+			continue
+		}
+		if repoDigests == nil {
+			digests, err := img.RepoDigests()
+			if err != nil {
+				logrus.Debugf("Getting repo digests of image %s: %v", img.ID(), err)
+				return ""
+			}
+			repoDigests = digests
+		}
+		repoDigest, err := reference.WithDigest(reference.TrimNamed(candidate), digested.Digest())
+		if err == nil && slices.Contains(repoDigests, repoDigest.String()) {
+			return repoDigest.String()
+		}
+	}
+	return ""
+}
+
+// candidateImages returns the IDs of the local images that candidates
+// currently refer to, by name.
+func (ir *ImageEngine) candidateImages(candidates []reference.Named) map[string]string {
+	images := make(map[string]string)
+	for _, candidate := range candidates {
+		if _, ok := candidate.(reference.Digested); ok {
+			// A digest always refers to the same image.
+			continue
+		}
+		if img, _, err := ir.Libpod.LibimageRuntime().LookupImage(candidate.String(), nil); err == nil {
+			images[candidate.String()] = img.ID()
+		}
+	}
+	return images
+}
+
+// recordPull writes a pull-record event with the digest, the duration and
+// the result for each image of report, or with the error if pullErr is set.
+func (ir *ImageEngine) recordPull(rawImage string, report *entities.ImagePullReport, pullErr error, duration time.Duration) {
+	if pullErr != nil {
+		ir.Libpod.NewImagePullRecordEvent(rawImage, "", "", duration, false, pullErr)
+		return
+	}
+	for _, id := range report.Images {
+		var imageDigest string
+		if img, _, err := ir.Libpod.LibimageRuntime().LookupImage(id, nil); err == nil {
+			imageDigest = img.Digest().String()
+		}
+		ir.Libpod.NewImagePullRecordEvent(rawImage, id, imageDigest, duration, report.CacheHit, nil)
+	}
+}
+
+// checkCheckpointCompatible warns about each of images whose containers
+// cannot be checkpointed and restored with CRIU on this host.
+func (ir *ImageEngine) checkCheckpointCompatible(ctx context.Context, images []*libimage.Image) {
+	criuInfo := ir.Libpod.CRIUInfo()
+	hostOS, hostArch, hostVariant, err := parse.Platform(parse.DefaultPlatform())
+	if err != nil {
+		logrus.Debugf("Parsing host platform: %v", err)
+		return
+	}
+	host := imgspecv1.Platform{OS: hostOS, Architecture: hostArch, Variant: hostVariant}
+	for _, img := range images {
+		ref, err := img.StorageReference()
+		if err != nil {
+			logrus.Debugf("Looking up image %s: %v", img.ID(), err)
+			continue
+		}
+		src, err := ref.NewImage(ctx, nil)
+		if err != nil {
+			logrus.Debugf("Reading image %s: %v", img.ID(), err)
+			continue
+		}
+		config, err := src.OCIConfig(ctx)
+		src.Close()
+		if err != nil {
+			logrus.Debugf("Reading config of image %s: %v", img.ID(), err)
+			continue
+		}
+		for _, problem := range checkpointProblems(config.Platform, host, criuInfo) {
+			logrus.Warnf("Containers of image %s cannot be checkpointed and restored: %s", img.ID(), problem)
+		}
+	}
+}
+
+// checkpointProblems returns why containers of an image for the platform
+// image cannot be restored with the CRIU installation described by
+// criuInfo on the host platform host.  Variants are only compared if both
+// platforms have one.
+func checkpointProblems(image, host imgspecv1.Platform, criuInfo *define.CRIUInfo) []string {
+	var problems []string
+	switch {
+	case criuInfo == nil:
+		problems = append(problems, "checkpoint/restore is not supported on this platform")
+	case !criuInfo.Functional:
+		problems = append(problems, fmt.Sprintf("CRIU is not functional: %s", criuInfo.Error))
+	}
+	if image.OS != host.OS || image.Architecture != host.Architecture ||
+		(image.Variant != "" && host.Variant != "" && image.Variant != host.Variant) {
+		problems = append(problems, fmt.Sprintf("the image is built for %s, but CRIU can only restore %s processes", platformString(image), platformString(host)))
+	}
+	return problems
+}
+
+// platformString formats platform as os/arch[/variant].
+func platformString(platform imgspecv1.Platform) string {
+	s := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		s += "/" + platform.Variant
+	}
+	return s
+}
+
+// freeSpace returns the number of free bytes of the file system at path.
+func freeSpace(path string) (uint64, error) {
+	var stats syscall.Statfs_t
+	if err := syscall.Statfs(path, &stats); err != nil {
+		return 0, fmt.Errorf("checking free space of %q: %w", path, err)
+	}
+	return uint64(stats.Bsize) * stats.Bfree, nil
+}
+
+// checkFreeSpace returns an error if less than minFree bytes are free on
+// the file system at path.
+func checkFreeSpace(path string, minFree uint64) error {
+	free, err := freeSpace(path)
+	if err != nil {
+		return err
+	}
+	if free < minFree {
+		return fmt.Errorf("not enough free space on %s: %s free, at least %s required", path, units.BytesSize(float64(free)), units.BytesSize(float64(minFree)))
+	}
+	return nil
+}
+
+// freeSpaceGuard checks the free space on path every time progress is
+// reported while copying and cancels the pull if it drops below minFree.
+// Progress events are forwarded to next if it is non-nil.  The returned
+// function stops the guard and returns the error that canceled the pull.
+func freeSpaceGuard(ctx context.Context, path string, minFree uint64, next chan types.ProgressProperties) (context.Context, chan types.ProgressProperties, func() error) {
+	ctx, cancel := context.WithCancel(ctx)
+	progress := make(chan types.ProgressProperties)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	var guardErr error
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case event := <-progress:
+				// Keep draining the channel after canceling, the
+				// copy may still report progress until it notices.
+				if guardErr == nil {
+					if guardErr = checkFreeSpace(path, minFree); guardErr != nil {
+						cancel()
+					}
+				}
+				if next != nil {
+					next <- event
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return ctx, progress, func() error {
+		close(stop)
+		<-done
+		cancel()
+		return guardErr
+	}
+}
+
+// blobRetryReference is an image reference whose image source retries
+// fetching blobs.
+type blobRetryReference struct {
+	types.ImageReference
+	maxRetries uint
+}
+
+func (r blobRetryReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	return &blobRetrySource{ImageSource: src, maxRetries: r.maxRetries}, nil
+}
+
+// blobRetrySource retries failed requests for blobs as well as failed reads
+// of their contents, for which the blob is fetched again and the part that
+// was already read is skipped.
+type blobRetrySource struct {
+	types.ImageSource
+	maxRetries uint
+}
+
+func (s *blobRetrySource) GetBlob(ctx context.Context, info types.BlobInfo, cache types.BlobInfoCache) (io.ReadCloser, int64, error) {
+	r := &blobRetryReader{ctx: ctx, src: s, info: info, cache: cache}
+	for {
+		rc, size, err := s.ImageSource.GetBlob(ctx, info, cache)
+		if err == nil {
+			r.rc = rc
+			return r, size, nil
+		}
+		if !r.retry(err) {
+			return nil, 0, err
+		}
+	}
+}
+
+// blobRetryReader reads a blob, fetching it again if reading fails.
+type blobRetryReader struct {
+	ctx     context.Context
+	src     *blobRetrySource
+	info    types.BlobInfo
+	cache   types.BlobInfoCache
+	rc      io.ReadCloser
+	offset  int64
+	retries uint
+	// err is the error reading failed with for good
+	err error
+}
+
+// blobRetryDelay is the delay before the first retry of a blob, the delay
+// grows linearly with every further retry.
+var blobRetryDelay = time.Second
+
+// retry returns whether the failed attempt should be retried, after
+// waiting for a moment.  A connection that is closed while reading the
+// blob is retried in addition to the errors retried for the whole pull.
+func (r *blobRetryReader) retry(err error) bool {
+	if r.retries >= r.src.maxRetries || (!errors.Is(err, io.ErrUnexpectedEOF) && !retry.IsErrorRetryable(err)) {
+		return false
+	}
+	r.retries++
+	delay := time.Duration(r.retries) * blobRetryDelay
+	logrus.Warnf("Failed to fetch blob %s, retrying in %s (%d/%d): %v", r.info.Digest, delay, r.retries, r.src.maxRetries, err)
+	select {
+	case <-time.After(delay):
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+func (r *blobRetryReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.rc.Read(p)
+	r.offset += int64(n)
+	if err == nil || errors.Is(err, io.EOF) {
+		return n, err
+	}
+	for r.retry(err) {
+		if err = r.reopen(); err == nil {
+			return n, nil
+		}
+	}
+	r.err = err
+	return n, err
+}
+
+// reopen fetches the blob again and skips the part that was already read.
+func (r *blobRetryReader) reopen() error {
+	if r.rc != nil {
+		r.rc.Close()
+		r.rc = nil
+	}
+	rc, _, err := r.src.ImageSource.GetBlob(r.ctx, r.info, r.cache)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, rc, r.offset); err != nil {
+		rc.Close()
+		return err
+	}
+	r.rc = rc
+	return nil
+}
+
+func (r *blobRetryReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	return r.rc.Close()
+}
+
+// registryWaitDelay is the delay before the first retry of waitForRegistry,
+// which is doubled after every attempt up to registryWaitMaxDelay.
+var (
+	registryWaitDelay    = 500 * time.Millisecond
+	registryWaitMaxDelay = 5 * time.Second
+)
+
+// pullRegistries returns the registries rawImage may be pulled from: its
+// domain if it is fully qualified, the unqualified-search registries for
+// short names, or none for other transports than docker.
+func pullRegistries(sys *types.SystemContext, rawImage string) ([]string, error) {
+	if ref, err := alltransports.ParseImageName(rawImage); err == nil {
+		if ref.Transport().Name() != docker.Transport.Name() {
+			return nil, nil
+		}
+		return []string{reference.Domain(ref.DockerReference())}, nil
+	}
+	if shortnames.IsShortName(rawImage) {
+		return sysregistriesv2.UnqualifiedSearchRegistries(sys)
+	}
+	named, err := reference.ParseNormalizedNamed(rawImage)
+	if err != nil {
+		return nil, err
+	}
+	return []string{reference.Domain(named)}, nil
+}
+
+// waitForRegistry polls the /v2/ endpoint of the registries until one of
+// them responds or timeout expires, backing off between attempts.  A
+// registry asking for credentials counts as reachable.
+func waitForRegistry(ctx context.Context, sys *types.SystemContext, registries []string, timeout time.Duration) error {
+	if len(registries) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	delay := registryWaitDelay
+	for {
+		var lastErr error
+		for _, registry := range registries {
+			err := docker.CheckAuth(ctx, sys, "", "", registry)
+			if err == nil || errors.As(err, &docker.ErrUnauthorizedForCredentials{}) {
+				logrus.Debugf("Registry %s is reachable", registry)
+				return nil
+			}
+			logrus.Debugf("Registry %s is not reachable yet: %v", registry, err)
+			lastErr = err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("registry %s unreachable after %s: %w", strings.Join(registries, ", "), timeout, lastErr)
+			}
+			return ctx.Err()
+		}
+		delay = min(2*delay, registryWaitMaxDelay)
+	}
+}
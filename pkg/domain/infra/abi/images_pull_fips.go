@@ -0,0 +1,148 @@
+package abi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+)
+
+// fipsCipherSuites are the TLS cipher suites approved for FIPS 140-3.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_AES_128_GCM_SHA256,
+	tls.TLS_AES_256_GCM_SHA384,
+}
+
+// fipsDigestAlgorithms are the digest algorithms approved for FIPS 140-3.
+var fipsDigestAlgorithms = []digest.Algorithm{digest.SHA256, digest.SHA384, digest.SHA512}
+
+// checkFIPSConnection returns an error if a TLS connection with the
+// specified state does not use a FIPS-approved version and cipher suite.
+func checkFIPSConnection(host string, state tls.ConnectionState) error {
+	if state.Version < tls.VersionTLS12 {
+		return fmt.Errorf("registry %s negotiated %s, FIPS mode requires TLS 1.2 or later", host, tls.VersionName(state.Version))
+	}
+	if !slices.Contains(fipsCipherSuites, state.CipherSuite) {
+		return fmt.Errorf("registry %s negotiated cipher suite %s, which is not approved in FIPS mode", host, tls.CipherSuiteName(state.CipherSuite))
+	}
+	return nil
+}
+
+// checkFIPSDigest returns an error if d does not use a FIPS-approved
+// algorithm.
+func checkFIPSDigest(d digest.Digest) error {
+	if !slices.Contains(fipsDigestAlgorithms, d.Algorithm()) {
+		return fmt.Errorf("digest %s uses algorithm %q, which is not approved in FIPS mode", d, d.Algorithm())
+	}
+	return nil
+}
+
+// fipsCheck returns ctx with a client trace checking every connection made
+// with it, and a function returning the first violation, if any.
+// containers/image does not allow for choosing the cipher suites, so the
+// negotiated ones are checked instead.
+func fipsCheck(ctx context.Context) (context.Context, func() error) {
+	var (
+		lock      sync.Mutex
+		host      string
+		violation error
+	)
+	clientTrace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			lock.Lock()
+			defer lock.Unlock()
+			host = hostPort
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			lock.Lock()
+			defer lock.Unlock()
+			if _, ok := info.Conn.(*tls.Conn); !ok && violation == nil {
+				violation = fmt.Errorf("registry %s is accessed without TLS, which is not allowed in FIPS mode", host)
+			}
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err != nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if violation == nil {
+				violation = checkFIPSConnection(host, state)
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, clientTrace), func() error {
+		lock.Lock()
+		defer lock.Unlock()
+		return violation
+	}
+}
+
+// fipsReference is an image reference whose image source checks that the
+// pull is FIPS-compliant.
+type fipsReference struct {
+	types.ImageReference
+}
+
+func (r fipsReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	ctx, violation := fipsCheck(ctx)
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err == nil {
+		if err = violation(); err != nil {
+			src.Close()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fipsSource{ImageSource: src}, nil
+}
+
+// fipsSource checks the connections made to read manifests and blobs, and
+// the digests they are read by.
+type fipsSource struct {
+	types.ImageSource
+}
+
+func (s *fipsSource) GetManifest(ctx context.Context, instanceDigest *digest.Digest) ([]byte, string, error) {
+	if instanceDigest != nil {
+		if err := checkFIPSDigest(*instanceDigest); err != nil {
+			return nil, "", err
+		}
+	}
+	ctx, violation := fipsCheck(ctx)
+	manifest, manifestType, err := s.ImageSource.GetManifest(ctx, instanceDigest)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := violation(); err != nil {
+		return nil, "", err
+	}
+	return manifest, manifestType, nil
+}
+
+func (s *fipsSource) GetBlob(ctx context.Context, info types.BlobInfo, cache types.BlobInfoCache) (io.ReadCloser, int64, error) {
+	if err := checkFIPSDigest(info.Digest); err != nil {
+		return nil, 0, err
+	}
+	ctx, violation := fipsCheck(ctx)
+	rc, size, err := s.ImageSource.GetBlob(ctx, info, cache)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := violation(); err != nil {
+		rc.Close()
+		return nil, 0, err
+	}
+	return rc, size, nil
+}
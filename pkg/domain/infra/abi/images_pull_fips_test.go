@@ -0,0 +1,57 @@
+package abi
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFIPSConnection(t *testing.T) {
+	assert.NoError(t, checkFIPSConnection("example.com", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_256_GCM_SHA384}))
+	assert.NoError(t, checkFIPSConnection("example.com", tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}))
+	assert.ErrorContains(t, checkFIPSConnection("example.com", tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_CHACHA20_POLY1305_SHA256}), "TLS_CHACHA20_POLY1305_SHA256")
+	assert.ErrorContains(t, checkFIPSConnection("example.com", tls.ConnectionState{Version: tls.VersionTLS11, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}), "TLS 1.1")
+}
+
+func TestCheckFIPSDigest(t *testing.T) {
+	assert.NoError(t, checkFIPSDigest(digest.FromString("blob")))
+	assert.NoError(t, checkFIPSDigest(digest.SHA512.FromString("blob")))
+	assert.ErrorContains(t, checkFIPSDigest("md5:1234"), `algorithm "md5"`)
+}
+
+func TestFIPSCheck(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tc := range []struct {
+		name      string
+		server    *httptest.Server
+		violation string
+	}{
+		{"plain", httptest.NewServer(handler), "without TLS"},
+		{"tls", httptest.NewTLSServer(handler), ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.server.Close()
+			client := tc.server.Client()
+			if transport, ok := client.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+				transport.TLSClientConfig.CipherSuites = []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+			}
+			ctx, violation := fipsCheck(context.Background())
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tc.server.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			if tc.violation == "" {
+				assert.NoError(t, violation())
+			} else {
+				assert.ErrorContains(t, violation(), tc.violation)
+			}
+		})
+	}
+}
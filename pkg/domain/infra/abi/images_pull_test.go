@@ -0,0 +1,313 @@
+package abi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullWarnings(t *testing.T) {
+	c := pullWarnings.collect()
+	logrus.Warn("first")
+	logrus.Info("not a warning")
+	logrus.Warnf("second %d", 2)
+	assert.Equal(t, []string{"first", "second 2"}, pullWarnings.stop(c))
+
+	logrus.Warn("after stop")
+	assert.Equal(t, []string{"first", "second 2"}, c.warnings)
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, checkFreeSpace(dir, 1))
+	assert.ErrorContains(t, checkFreeSpace(dir, math.MaxUint64), "not enough free space on "+dir)
+}
+
+// flakyBlobSource serves a blob whose first reads fail after half of it.
+type flakyBlobSource struct {
+	types.ImageSource
+	blob     []byte
+	failures int
+	requests int
+}
+
+type failingReader struct {
+	io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (s *flakyBlobSource) GetBlob(ctx context.Context, info types.BlobInfo, cache types.BlobInfoCache) (io.ReadCloser, int64, error) {
+	s.requests++
+	if s.requests <= s.failures {
+		return io.NopCloser(failingReader{bytes.NewReader(s.blob[:len(s.blob)/2])}), int64(len(s.blob)), nil
+	}
+	return io.NopCloser(bytes.NewReader(s.blob)), int64(len(s.blob)), nil
+}
+
+func TestBlobRetrySource(t *testing.T) {
+	blobRetryDelay = time.Millisecond
+	blob := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+	flaky := &flakyBlobSource{blob: blob, failures: 2}
+	src := &blobRetrySource{ImageSource: flaky, maxRetries: 2}
+	rc, _, err := src.GetBlob(context.Background(), types.BlobInfo{}, nil)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, blob, data)
+	assert.Equal(t, 3, flaky.requests)
+	assert.NoError(t, rc.Close())
+
+	flaky = &flakyBlobSource{blob: blob, failures: 2}
+	src = &blobRetrySource{ImageSource: flaky, maxRetries: 1}
+	rc, _, err = src.GetBlob(context.Background(), types.BlobInfo{}, nil)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, 2, flaky.requests)
+	assert.NoError(t, rc.Close())
+}
+
+func TestWaitForRegistry(t *testing.T) {
+	delay := registryWaitDelay
+	registryWaitDelay = 10 * time.Millisecond
+	defer func() { registryWaitDelay = delay }()
+
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	sys := &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue}
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	require.NoError(t, waitForRegistry(context.Background(), sys, []string{registry}, time.Minute))
+	assert.GreaterOrEqual(t, requests.Load(), int32(3))
+
+	server.Close()
+	err := waitForRegistry(context.Background(), sys, []string{registry}, 100*time.Millisecond)
+	assert.ErrorContains(t, err, "registry "+registry+" unreachable after 100ms")
+}
+
+func TestPullRegistries(t *testing.T) {
+	sys := &types.SystemContext{}
+	registries, err := pullRegistries(sys, "quay.io/libpod/alpine")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"quay.io"}, registries)
+
+	registries, err = pullRegistries(sys, "docker://localhost:5000/alpine:latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost:5000"}, registries)
+
+	registries, err = pullRegistries(sys, "oci-archive:/tmp/alpine.tar")
+	require.NoError(t, err)
+	assert.Empty(t, registries)
+}
+
+func TestPullCandidates(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "registries.conf")
+	require.NoError(t, os.WriteFile(conf, []byte(`unqualified-search-registries = ["quay.io", "docker.io"]`), 0o644))
+	sys := &types.SystemContext{SystemRegistriesConfPath: conf, SystemRegistriesConfDirPath: "/dev/null"}
+
+	var names []string
+	for _, candidate := range pullCandidates(sys, "alpine") {
+		names = append(names, candidate.String())
+	}
+	assert.Equal(t, []string{"localhost/alpine:latest", "quay.io/alpine:latest", "docker.io/library/alpine:latest"}, names)
+
+	candidates := pullCandidates(sys, "docker://quay.io/libpod/alpine@sha256:"+strings.Repeat("a", 64))
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "quay.io/libpod/alpine@sha256:"+strings.Repeat("a", 64), candidates[0].String())
+
+	assert.Empty(t, pullCandidates(sys, "oci-archive:/tmp/alpine.tar"))
+}
+
+func TestChainDockerLookup(t *testing.T) {
+	wrap := func(ref types.ImageReference) types.ImageReference {
+		return mirrorOnlyReference{ImageReference: ref}
+	}
+	lookup := chainDockerLookup(chainDockerLookup(nil, wrap), wrap)
+
+	ref, err := alltransports.ParseImageName("docker://quay.io/libpod/alpine:latest")
+	require.NoError(t, err)
+	wrapped, err := lookup(ref)
+	require.NoError(t, err)
+	require.IsType(t, mirrorOnlyReference{}, wrapped)
+	assert.IsType(t, mirrorOnlyReference{}, wrapped.(mirrorOnlyReference).ImageReference)
+
+	ref, err = alltransports.ParseImageName("oci-archive:/tmp/alpine.tar")
+	require.NoError(t, err)
+	wrapped, err = lookup(ref)
+	require.NoError(t, err)
+	assert.Equal(t, ref, wrapped)
+
+	failing := func(types.ImageReference) (types.ImageReference, error) {
+		return nil, errors.New("lookup failed")
+	}
+	_, err = chainDockerLookup(failing, wrap)(ref)
+	assert.EqualError(t, err, "lookup failed")
+}
+
+func TestMirrorOnlyReference(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "registries.conf")
+	require.NoError(t, os.WriteFile(conf, []byte(`
+[[registry]]
+location = "example.com"
+
+[[registry.mirror]]
+location = "127.0.0.1:9/mirror"
+insecure = true
+`), 0o644))
+	sys := &types.SystemContext{SystemRegistriesConfPath: conf}
+
+	ref, err := alltransports.ParseImageName("docker://quay.io/libpod/alpine:latest")
+	require.NoError(t, err)
+	_, err = mirrorOnlyReference{ImageReference: ref}.NewImageSource(context.Background(), sys)
+	assert.EqualError(t, err, "no mirror is configured for quay.io/libpod/alpine:latest, pulling it would contact the upstream registry quay.io")
+
+	ref, err = alltransports.ParseImageName("docker://example.com/alpine:latest")
+	require.NoError(t, err)
+	_, err = mirrorOnlyReference{ImageReference: ref}.NewImageSource(context.Background(), sys)
+	assert.ErrorContains(t, err, "no mirror serves example.com/alpine:latest, not falling back to the upstream registry example.com: 127.0.0.1:9/mirror/alpine:latest: ")
+}
+
+func TestChooseGzipInstance(t *testing.T) {
+	sys := &types.SystemContext{OSChoice: "linux", ArchitectureChoice: "amd64"}
+	index := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:1111111111111111111111111111111111111111111111111111111111111111","size":1,"platform":{"os":"linux","architecture":"amd64"},"annotations":{"io.github.containers.compression.zstd":"true"}},
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:2222222222222222222222222222222222222222222222222222222222222222","size":1,"platform":{"os":"linux","architecture":"amd64"}},
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:3333333333333333333333333333333333333333333333333333333333333333","size":1,"platform":{"os":"linux","architecture":"arm64"},"annotations":{"io.github.containers.compression.zstd":"true"}}]}`
+
+	instance, err := chooseGzipInstance(sys, []byte(index), imgspecv1.MediaTypeImageIndex)
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:2222222222222222222222222222222222222222222222222222222222222222"), instance)
+
+	// Without a gzip instance for the platform, the zstd one is chosen.
+	sys.ArchitectureChoice = "arm64"
+	instance, err = chooseGzipInstance(sys, []byte(index), imgspecv1.MediaTypeImageIndex)
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:3333333333333333333333333333333333333333333333333333333333333333"), instance)
+}
+
+func TestChooseVariantInstance(t *testing.T) {
+	sys := &types.SystemContext{OSChoice: "linux", ArchitectureChoice: "arm"}
+	index := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:1111111111111111111111111111111111111111111111111111111111111111","size":1,"platform":{"os":"linux","architecture":"arm","variant":"v6"}},
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:2222222222222222222222222222222222222222222222222222222222222222","size":1,"platform":{"os":"linux","architecture":"arm","variant":"v7"}},
+	{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:3333333333333333333333333333333333333333333333333333333333333333","size":1,"platform":{"os":"linux","architecture":"arm64","variant":"v8"}}]}`
+
+	instance, variant, err := chooseVariantInstance(sys, []byte(index), imgspecv1.MediaTypeImageIndex, []string{"v8", "v7", "v6"})
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:2222222222222222222222222222222222222222222222222222222222222222"), instance)
+	assert.Equal(t, "v7", variant)
+
+	instance, variant, err = chooseVariantInstance(sys, []byte(index), imgspecv1.MediaTypeImageIndex, []string{"v6", "v7"})
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:1111111111111111111111111111111111111111111111111111111111111111"), instance)
+	assert.Equal(t, "v6", variant)
+
+	// Without any of the variants, containers/image chooses.
+	sys.ArchitectureChoice = "arm64"
+	instance, variant, err = chooseVariantInstance(sys, []byte(index), imgspecv1.MediaTypeImageIndex, []string{"v9"})
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:3333333333333333333333333333333333333333333333333333333333333333"), instance)
+	assert.Empty(t, variant)
+}
+
+func TestCheckpointProblems(t *testing.T) {
+	host := imgspecv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	criuInfo := &define.CRIUInfo{Functional: true}
+
+	assert.Empty(t, checkpointProblems(imgspecv1.Platform{OS: "linux", Architecture: "arm64"}, host, criuInfo))
+	assert.Equal(t, []string{"the image is built for linux/arm/v7, but CRIU can only restore linux/arm64/v8 processes"},
+		checkpointProblems(imgspecv1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, host, criuInfo))
+	assert.Equal(t, []string{"the image is built for linux/arm64/v9, but CRIU can only restore linux/arm64/v8 processes"},
+		checkpointProblems(imgspecv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v9"}, host, criuInfo))
+
+	criuInfo = &define.CRIUInfo{Error: "exec: \"criu\": executable file not found in $PATH"}
+	assert.Equal(t, []string{"CRIU is not functional: exec: \"criu\": executable file not found in $PATH"},
+		checkpointProblems(host, host, criuInfo))
+	assert.Equal(t, []string{"checkpoint/restore is not supported on this platform"}, checkpointProblems(host, host, nil))
+}
+
+func TestCheckStrictPlatform(t *testing.T) {
+	provided := []imgspecv1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm", Variant: "v6"},
+		{OS: "linux", Architecture: "arm", Variant: "v6"},
+	}
+	assert.NoError(t, checkStrictPlatform("example.com/alpine", imgspecv1.Platform{OS: "linux", Architecture: "amd64"}, provided))
+	assert.NoError(t, checkStrictPlatform("example.com/alpine", imgspecv1.Platform{OS: "linux", Architecture: "arm"}, provided))
+
+	err := checkStrictPlatform("example.com/alpine", imgspecv1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, provided)
+	assert.EqualError(t, err, "example.com/alpine does not provide the platform linux/arm/v7, only linux/amd64, linux/arm/v6")
+	err = checkStrictPlatform("example.com/alpine", imgspecv1.Platform{OS: "linux", Architecture: "arm64"}, nil)
+	assert.EqualError(t, err, "example.com/alpine does not provide the platform linux/arm64")
+}
+
+func TestCheckGzipLayers(t *testing.T) {
+	image := `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",
+	"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:0000000000000000000000000000000000000000000000000000000000000000","size":1},
+	"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":"sha256:1111111111111111111111111111111111111111111111111111111111111111","size":1},
+	{"mediaType":"%s","digest":"sha256:2222222222222222222222222222222222222222222222222222222222222222","size":1}]}`
+
+	assert.NoError(t, checkGzipLayers("example.com/alpine", []byte(fmt.Sprintf(image, imgspecv1.MediaTypeImageLayer)), imgspecv1.MediaTypeImageManifest))
+	err := checkGzipLayers("example.com/alpine", []byte(fmt.Sprintf(image, imgspecv1.MediaTypeImageLayerZstd)), imgspecv1.MediaTypeImageManifest)
+	assert.EqualError(t, err, "layer sha256:2222222222222222222222222222222222222222222222222222222222222222 of example.com/alpine is zstd-compressed, but only gzip-compressed layers are accepted with --accept-gzip-only")
+}
+
+func TestSharedLayers(t *testing.T) {
+	layers := []types.BlobInfo{{Digest: "sha256:b1"}, {Digest: "sha256:b2"}, {Digest: "sha256:b3"}}
+	diffIDs := []digest.Digest{"sha256:d1", "sha256:d2", "sha256:d3"}
+
+	shared, err := sharedLayers(layers, diffIDs, []digest.Digest{"sha256:d1", "sha256:d2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[digest.Digest]digest.Digest{"sha256:b1": "sha256:d1", "sha256:b2": "sha256:d2"}, shared)
+
+	// Layers above a different one are not shared, even if they match.
+	shared, err = sharedLayers(layers, diffIDs, []digest.Digest{"sha256:d1", "sha256:other", "sha256:d3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[digest.Digest]digest.Digest{"sha256:b1": "sha256:d1"}, shared)
+
+	_, err = sharedLayers(layers, diffIDs[:2], diffIDs)
+	assert.EqualError(t, err, "image has 3 layers but 2 diff IDs")
+}
+
+func TestCheckDiffIDs(t *testing.T) {
+	diffIDs := []digest.Digest{"sha256:d1", "sha256:d2"}
+	assert.NoError(t, checkDiffIDs(diffIDs, []digest.Digest{"sha256:d1", "sha256:d2"}))
+	assert.EqualError(t, checkDiffIDs(diffIDs, []digest.Digest{"sha256:d2", "sha256:d1"}), "layer 1 has diff ID sha256:d1, expected sha256:d2")
+	assert.EqualError(t, checkDiffIDs(diffIDs, []digest.Digest{"sha256:d1"}), "image has 2 layers, expected 1")
+	assert.EqualError(t, checkDiffIDs(diffIDs[:1], diffIDs), "image has 1 layers, expected 2")
+}
@@ -0,0 +1,420 @@
+package abi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/pkg/docker/config"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/pkg/tlsclientconfig"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/storage/pkg/fileutils"
+	"github.com/containers/storage/pkg/homedir"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/sirupsen/logrus"
+)
+
+// tokenMinValidity is how long a cached token must remain valid to be
+// used.  containers/image does not renew a token passed to it, so requests
+// made after it expired fail.
+var tokenMinValidity = time.Minute
+
+// tokenDefaultExpiry is the lifetime of tokens issued without an expiry,
+// as defined by the distribution token specification.
+const tokenDefaultExpiry = 60 * time.Second
+
+// tokenCache caches registry bearer tokens in a directory, so that pulls
+// from the same repository in later invocations can skip fetching one.
+type tokenCache struct {
+	dir string
+}
+
+// tokenScope identifies the repository and the user a token is for.
+type tokenScope struct {
+	// Registry is the host and port of the registry
+	Registry   string
+	Repository string
+	Username   string
+	Password   string
+}
+
+// cachedToken is the content of a file in the token cache.
+type cachedToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// newTokenCache returns a cache of the bearer tokens stored in dir, which
+// is created if missing and must only be accessible by the current user.
+func newTokenCache(dir string) (*tokenCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating token cache directory: %w", err)
+	}
+	st, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if st.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("token cache directory %s must not be accessible by other users, its permissions are %#o", dir, st.Mode().Perm())
+	}
+	return &tokenCache{dir: dir}, nil
+}
+
+// tokenCacheReference is an image reference whose image source uses a
+// cached bearer token.
+type tokenCacheReference struct {
+	types.ImageReference
+	cache *tokenCache
+}
+
+// NewImageSource returns the image source of the wrapped reference,
+// authenticated with a cached or newly cached token.  If no token can be
+// used, authentication is left to containers/image.
+func (r tokenCacheReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	scope, err := pullTokenScope(sys, r.DockerReference())
+	if err != nil || scope == nil {
+		if err != nil {
+			logrus.Debugf("Not using the token cache for %s: %v", r.DockerReference(), err)
+		}
+		return r.ImageReference.NewImageSource(ctx, sys)
+	}
+	token, err := r.cache.token(ctx, sys, scope)
+	if err != nil || token == "" {
+		if err != nil {
+			logrus.Debugf("Not using the token cache for %s: %v", r.DockerReference(), err)
+		}
+		return r.ImageReference.NewImageSource(ctx, sys)
+	}
+
+	var tokenSys types.SystemContext
+	if sys != nil {
+		tokenSys = *sys
+	}
+	tokenSys.DockerBearerRegistryToken = token
+	src, err := r.ImageReference.NewImageSource(ctx, &tokenSys)
+	if err == nil {
+		return src, nil
+	}
+	// The token may have been revoked, try again without it.
+	logrus.Debugf("Accessing %s with a cached token failed: %v", r.DockerReference(), err)
+	r.cache.remove(scope)
+	return r.ImageReference.NewImageSource(ctx, sys)
+}
+
+// pullTokenScope returns the scope of the token for pulling named, or nil
+// if tokens for it are not cached: for registries with mirrors, which must
+// not receive the token of the registry, insecure registries, and OAuth2
+// identity tokens.
+func pullTokenScope(sys *types.SystemContext, named reference.Named) (*tokenScope, error) {
+	registry, err := sysregistriesv2.FindRegistry(sys, named.Name())
+	if err != nil {
+		return nil, err
+	}
+	if registry != nil {
+		if len(registry.Mirrors) > 0 || registry.Insecure {
+			return nil, nil
+		}
+		sources, err := registry.PullSourcesFromReference(named)
+		if err != nil {
+			return nil, err
+		}
+		// Apply the location of the registry to the reference.
+		named = sources[len(sources)-1].Reference
+	}
+
+	scope := &tokenScope{Registry: reference.Domain(named), Repository: reference.Path(named)}
+	var auth types.DockerAuthConfig
+	if sys != nil && sys.DockerAuthConfig != nil {
+		auth = *sys.DockerAuthConfig
+	} else if auth, err = config.GetCredentials(sys, scope.Registry); err != nil {
+		return nil, err
+	}
+	if auth.IdentityToken != "" {
+		return nil, nil
+	}
+	scope.Username = auth.Username
+	scope.Password = auth.Password
+	return scope, nil
+}
+
+// path returns the path of the file caching the token for scope.  The
+// name is a hash, so it does not reveal the repositories pulled.
+func (c *tokenCache) path(scope *tokenScope) string {
+	sum := sha256.Sum256([]byte(scope.Registry + "\x00" + scope.Repository + "\x00" + scope.Username))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// token returns a token for scope that is valid for at least
+// tokenMinValidity, from the cache or fetched from the registry and
+// added to the cache.  It returns an empty token if the registry does not
+// use bearer tokens.
+func (c *tokenCache) token(ctx context.Context, sys *types.SystemContext, scope *tokenScope) (string, error) {
+	path := c.path(scope)
+	if data, err := os.ReadFile(path); err == nil {
+		var cached cachedToken
+		if err := json.Unmarshal(data, &cached); err != nil {
+			logrus.Debugf("Ignoring invalid cached token %s: %v", path, err)
+		} else if time.Until(cached.Expires) >= tokenMinValidity {
+			logrus.Debugf("Using cached token for %s/%s", scope.Registry, scope.Repository)
+			return cached.Token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	fetched, err := fetchPullToken(ctx, sys, scope, "")
+	if err != nil || fetched == nil {
+		return "", err
+	}
+	if err := c.store(path, fetched); err != nil {
+		logrus.Warnf("Failed to cache token for %s/%s: %v", scope.Registry, scope.Repository, err)
+	}
+	if time.Until(fetched.Expires) < tokenMinValidity {
+		return "", nil
+	}
+	return fetched.Token, nil
+}
+
+// store writes token to path, readable by the owner only.  It is written
+// to a temporary file first, so concurrent pulls never read a partial file.
+func (c *tokenCache) store(path string, token *cachedToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// remove drops the cached token for scope.
+func (c *tokenCache) remove(scope *tokenScope) {
+	if err := os.Remove(c.path(scope)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logrus.Debugf("Failed to remove cached token: %v", err)
+	}
+}
+
+// bearerChallengeParam matches a parameter of a WWW-Authenticate header.
+var bearerChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchPullToken requests a token for pulling from the repository of scope
+// from the authorization server announced by the registry, or from authHost
+// instead of the host of the announced realm if set.  It returns nil if the
+// registry does not ask for a bearer token.
+func fetchPullToken(ctx context.Context, sys *types.SystemContext, scope *tokenScope, authHost string) (*cachedToken, error) {
+	client, err := tokenHTTPClient(sys, scope)
+	if err != nil {
+		return nil, err
+	}
+	host := scope.Registry
+	if host == "docker.io" {
+		// Like containers/image, talk to the actual Docker Hub registry.
+		host = "registry-1.docker.io"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+"/v2/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, nil
+	}
+	params := make(map[string]string)
+	for _, match := range bearerChallengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	if params["realm"] == "" {
+		return nil, fmt.Errorf("registry %s sent a bearer challenge without realm", scope.Registry)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing token realm: %w", err)
+	}
+	if authHost != "" {
+		logrus.Debugf("Redirecting token request for %s from %s to %s", scope.Registry, tokenURL.Host, authHost)
+		tokenURL.Host = authHost
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", scope.Repository))
+	tokenURL.RawQuery = query.Encode()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if scope.Username != "" {
+		req.SetBasicAuth(scope.Username, scope.Password)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting token from %s: %s", tokenURL.Host, resp.Status)
+	}
+	var body struct {
+		Token       string    `json:"token"`
+		AccessToken string    `json:"access_token"`
+		ExpiresIn   int       `json:"expires_in"`
+		IssuedAt    time.Time `json:"issued_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding token: %w", err)
+	}
+	token := &cachedToken{Token: body.Token}
+	if token.Token == "" {
+		token.Token = body.AccessToken
+	}
+	if token.Token == "" {
+		return nil, fmt.Errorf("token server %s returned no token", tokenURL.Host)
+	}
+	issued := body.IssuedAt
+	if issued.IsZero() {
+		issued = time.Now()
+	}
+	expiry := time.Duration(body.ExpiresIn) * time.Second
+	if expiry < tokenDefaultExpiry {
+		expiry = tokenDefaultExpiry
+	}
+	token.Expires = issued.Add(expiry)
+	return token, nil
+}
+
+// checkAuthRedirectHost returns an error if host, which bearer tokens are
+// requested from instead of the host of the realm announced by the
+// registry, is not a host name with an optional port.
+func checkAuthRedirectHost(host string) error {
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return fmt.Errorf("invalid authentication host %q, must be a host name with an optional port", host)
+	}
+	return nil
+}
+
+// authRedirectReference is an image reference whose image source is
+// authenticated with a token requested from a redirected realm.
+type authRedirectReference struct {
+	types.ImageReference
+	host string
+}
+
+// NewImageSource returns the image source of the wrapped reference,
+// authenticated with a token from the redirected realm.  The token is not
+// renewed, so it must remain valid for the whole pull.
+func (r authRedirectReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	scope, err := pullTokenScope(sys, r.DockerReference())
+	if err != nil {
+		return nil, fmt.Errorf("redirecting authentication for %s: %w", r.DockerReference(), err)
+	}
+	if scope == nil {
+		return nil, fmt.Errorf("authentication for %s cannot be redirected: registries with mirrors, insecure registries and identity tokens are not supported", r.DockerReference())
+	}
+	token, err := fetchPullToken(ctx, sys, scope, r.host)
+	if err != nil {
+		return nil, fmt.Errorf("redirecting authentication for %s: %w", r.DockerReference(), err)
+	}
+	if token == nil {
+		return nil, fmt.Errorf("authentication for %s cannot be redirected: registry %s does not ask for a bearer token", r.DockerReference(), scope.Registry)
+	}
+
+	var tokenSys types.SystemContext
+	if sys != nil {
+		tokenSys = *sys
+	}
+	tokenSys.DockerBearerRegistryToken = token.Token
+	return r.ImageReference.NewImageSource(ctx, &tokenSys)
+}
+
+// tokenHTTPClient returns an HTTP client trusting the same certificates as
+// the containers/image client pulling from the repository of scope.
+func tokenHTTPClient(sys *types.SystemContext, scope *tokenScope) (*http.Client, error) {
+	tlsConfig := &tls.Config{CipherSuites: tlsconfig.DefaultServerAcceptedCiphers}
+	certDir, err := registryCertDir(sys, scope.Registry)
+	if err != nil {
+		return nil, err
+	}
+	if err := tlsclientconfig.SetupCertificates(certDir, tlsConfig); err != nil {
+		return nil, err
+	}
+	reg, err := sysregistriesv2.FindRegistry(sys, scope.Registry+"/"+scope.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("loading registries: %w", err)
+	}
+	if reg != nil {
+		tlsConfig.InsecureSkipVerify = reg.Insecure
+	}
+	if sys != nil && sys.DockerInsecureSkipTLSVerify != types.OptionalBoolUndefined {
+		tlsConfig.InsecureSkipVerify = sys.DockerInsecureSkipTLSVerify == types.OptionalBoolTrue
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: time.Minute}, nil
+}
+
+// registryCertDir returns the directory of the certificates for registry,
+// chosen like containers/image does: DockerCertPath or the directory of the
+// registry in DockerPerHostCertDirPath if set, otherwise the first existing
+// directory of the registry in the certs.d directories of the user, of
+// containers and of Docker.
+func registryCertDir(sys *types.SystemContext, registry string) (string, error) {
+	if sys != nil && sys.DockerCertPath != "" {
+		return sys.DockerCertPath, nil
+	}
+	if sys != nil && sys.DockerPerHostCertDirPath != "" {
+		return filepath.Join(sys.DockerPerHostCertDirPath, registry), nil
+	}
+	systemDirs := []string{filepath.Join(etcDir, "containers", "certs.d"), filepath.Join(etcDir, "docker", "certs.d")}
+	if sys != nil && sys.RootForImplicitAbsolutePaths != "" {
+		for i, dir := range systemDirs {
+			systemDirs[i] = filepath.Join(sys.RootForImplicitAbsolutePaths, dir)
+		}
+	}
+	var certDir string
+	for _, dir := range append([]string{filepath.Join(homedir.Get(), ".config", "containers", "certs.d")}, systemDirs...) {
+		certDir = filepath.Join(dir, registry)
+		err := fileutils.Exists(certDir)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if errors.Is(err, os.ErrPermission) {
+			logrus.Debugf("Accessing certificates directory %s: %v", certDir, err)
+			continue
+		}
+		return "", err
+	}
+	return certDir, nil
+}
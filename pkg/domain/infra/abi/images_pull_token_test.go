@@ -0,0 +1,149 @@
+package abi
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCache(t *testing.T) {
+	var tokens atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			assert.Equal(t, "test", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:libpod/alpine:pull", r.URL.Query().Get("scope"))
+			user, password, _ := r.BasicAuth()
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "secret", password)
+			fmt.Fprintf(w, `{"token":"token-%d","expires_in":300}`, tokens.Add(1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sys := &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue}
+	scope := &tokenScope{
+		Registry:   strings.TrimPrefix(server.URL, "https://"),
+		Repository: "libpod/alpine",
+		Username:   "user",
+		Password:   "secret",
+	}
+	cache := &tokenCache{dir: t.TempDir()}
+
+	token, err := cache.token(context.Background(), sys, scope)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	st, err := os.Stat(cache.path(scope))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+
+	token, err = cache.token(context.Background(), sys, scope)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), tokens.Load())
+
+	cache.remove(scope)
+	token, err = cache.token(context.Background(), sys, scope)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
+
+func TestNewTokenCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	_, err := newTokenCache(dir)
+	require.NoError(t, err)
+	st, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), st.Mode().Perm())
+
+	require.NoError(t, os.Chmod(dir, 0o755))
+	_, err = newTokenCache(dir)
+	assert.ErrorContains(t, err, "must not be accessible by other users")
+}
+
+func TestTokenHTTPClientCertDir(t *testing.T) {
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	scope := &tokenScope{Registry: host, Repository: "libpod/alpine"}
+	registriesConf := filepath.Join(t.TempDir(), "registries.conf")
+	require.NoError(t, os.WriteFile(registriesConf, nil, 0o644))
+
+	// The certificate of the registry is only trusted through the
+	// certificates directory.
+	certsDir := t.TempDir()
+	sys := &types.SystemContext{SystemRegistriesConfPath: registriesConf, DockerPerHostCertDirPath: certsDir}
+	_, err := fetchPullToken(context.Background(), sys, scope, "")
+	assert.ErrorContains(t, err, "certificate")
+
+	require.NoError(t, os.Mkdir(filepath.Join(certsDir, host), 0o755))
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: registry.Certificate().Raw})
+	require.NoError(t, os.WriteFile(filepath.Join(certsDir, host, "ca.crt"), cert, 0o644))
+	token, err := fetchPullToken(context.Background(), sys, scope, "")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+}
+
+func TestRegistryCertDir(t *testing.T) {
+	dir, err := registryCertDir(&types.SystemContext{DockerCertPath: "/certs"}, "quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, "/certs", dir)
+
+	dir, err = registryCertDir(&types.SystemContext{DockerPerHostCertDirPath: "/certs.d"}, "quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, "/certs.d/quay.io", dir)
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, etcDir, "docker", "certs.d", "quay.io"), 0o755))
+	dir, err = registryCertDir(&types.SystemContext{RootForImplicitAbsolutePaths: root}, "quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, etcDir, "docker", "certs.d", "quay.io"), dir)
+}
+
+func TestFetchPullTokenRedirect(t *testing.T) {
+	var redirected atomic.Bool
+	authServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/token", r.URL.Path)
+		assert.Equal(t, "repository:libpod/alpine:pull", r.URL.Query().Get("scope"))
+		redirected.Store(true)
+		fmt.Fprint(w, `{"token":"redirected","expires_in":300}`)
+	}))
+	defer authServer.Close()
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The announced realm is unreachable.
+		w.Header().Set("WWW-Authenticate", `Bearer realm="https://auth.invalid/token",service="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	sys := &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue}
+	scope := &tokenScope{Registry: strings.TrimPrefix(registry.URL, "https://"), Repository: "libpod/alpine"}
+	token, err := fetchPullToken(context.Background(), sys, scope, strings.TrimPrefix(authServer.URL, "https://"))
+	require.NoError(t, err)
+	assert.Equal(t, "redirected", token.Token)
+	assert.True(t, redirected.Load())
+}
+
+func TestCheckAuthRedirectHost(t *testing.T) {
+	require.NoError(t, checkAuthRedirectHost("auth.example.com:5000"))
+	for _, host := range []string{"", "https://auth.example.com", "auth.example.com/token"} {
+		assert.ErrorContains(t, checkAuthRedirectHost(host), "invalid authentication host", host)
+	}
+}
@@ -0,0 +1,324 @@
+package abi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/types"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+)
+
+// pullTraceRecord is a line of the trace written with --trace.  A record of
+// type "request" describes an HTTP request, one of type "operation" the
+// access to the registry the requests before it with the same operation
+// and digest were made for.
+type pullTraceRecord struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Image     string    `json:"image"`
+	Operation string    `json:"operation"`
+	Digest    string    `json:"digest,omitempty"`
+
+	// Host is the host and port the request was sent to
+	Host string `json:"host,omitempty"`
+	// Authorized is true if the request had an Authorization header
+	Authorized bool `json:"authorized,omitempty"`
+	// RedirectedFrom is the URL, without query and credentials, of the
+	// request whose redirect the request follows
+	RedirectedFrom   string `json:"redirectedFrom,omitempty"`
+	ReusedConnection bool   `json:"reusedConnection,omitempty"`
+	DNS              string `json:"dns,omitempty"`
+	Connect          string `json:"connect,omitempty"`
+	TLSHandshake     string `json:"tlsHandshake,omitempty"`
+	// FirstByte is the time from the start of the request to the first
+	// byte of the response, empty if there was no response
+	FirstByte string `json:"firstByte,omitempty"`
+
+	Duration string `json:"duration,omitempty"`
+	// ContentLength is the length of a blob announced by the registry,
+	// empty if it is unknown
+	ContentLength int64 `json:"contentLength,omitempty"`
+	// Size is the length of the manifest or the number of bytes of the
+	// blob read
+	Size int64 `json:"size,omitempty"`
+	// StatusCode is the HTTP status of the last response of an
+	// operation.  It is 200 for manifests and blobs read successfully and
+	// empty if a failed operation does not reveal it.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// pullTracer writes the trace records of a pull as JSON lines.
+type pullTracer struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (t *pullTracer) write(record *pullTraceRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		logrus.Debugf("Encoding trace record: %v", err)
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, err := t.w.Write(append(data, '\n')); err != nil {
+		logrus.Debugf("Writing trace record: %v", err)
+	}
+}
+
+// operation writes the record of an operation started at start, which
+// read size bytes of a blob of contentLength bytes, if known.
+func (t *pullTracer) operation(image, operation string, blob digest.Digest, start time.Time, contentLength, size int64, err error) {
+	record := &pullTraceRecord{
+		Time:      start,
+		Type:      "operation",
+		Image:     image,
+		Operation: operation,
+		Digest:    blob.String(),
+		Duration:  time.Since(start).String(),
+		Size:      size,
+	}
+	if contentLength > 0 {
+		record.ContentLength = contentLength
+	}
+	switch {
+	case err != nil:
+		record.Error = err.Error()
+		record.StatusCode = traceStatusCode(err)
+	case operation == "manifest" || operation == "blob":
+		// containers/image fails on any other status.
+		record.StatusCode = http.StatusOK
+	}
+	t.write(record)
+}
+
+// traceStatusCode returns the HTTP status of the response err was created
+// for, or 0 if it cannot be told.  containers/image creates its own HTTP
+// client and does not return the responses, so the status is only known
+// from the errors it returns for some of them.
+func traceStatusCode(err error) int {
+	var unauthorized docker.ErrUnauthorizedForCredentials
+	var registryErrs errcode.Errors
+	var coder errcode.ErrorCoder
+	switch {
+	case errors.As(err, &unauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, docker.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.As(err, &registryErrs) && len(registryErrs) > 0:
+		// containers/image reports the first error of a response.
+		err = registryErrs[0]
+	}
+	// Error codes unknown to the client do not tell the status.
+	if errors.As(err, &coder) && coder.ErrorCode() != errcode.ErrorCodeUnknown {
+		return coder.ErrorCode().Descriptor().HTTPStatusCode
+	}
+	return 0
+}
+
+// traceURL returns rawURL without its query, fragment and credentials,
+// which may contain tokens.
+func traceURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// trace returns ctx with a client trace writing a record for every HTTP
+// request made with it, and a function writing the record of a request
+// still waiting for a response.  containers/image makes the requests of a
+// single operation one after the other, so at most one is in flight.
+func (t *pullTracer) trace(ctx context.Context, image, operation string, blob digest.Digest) (context.Context, func()) {
+	var (
+		lock                                 sync.Mutex
+		record                               *pullTraceRecord
+		start, dnsStart, connStart, tlsStart time.Time
+	)
+	finish := func() {
+		if record != nil {
+			t.write(record)
+			record = nil
+		}
+	}
+	// The hooks are called without the lock held and, for dialing, on
+	// other goroutines.
+	update := func(fn func()) {
+		lock.Lock()
+		defer lock.Unlock()
+		if record != nil {
+			fn()
+		}
+	}
+	clientTrace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			lock.Lock()
+			defer lock.Unlock()
+			finish()
+			start = time.Now()
+			record = &pullTraceRecord{Time: start, Type: "request", Image: image, Operation: operation, Digest: blob.String(), Host: hostPort}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			update(func() { record.ReusedConnection = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			update(func() { dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			update(func() { record.DNS = time.Since(dnsStart).String() })
+		},
+		ConnectStart: func(string, string) {
+			update(func() { connStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			update(func() { record.Connect = time.Since(connStart).String() })
+		},
+		TLSHandshakeStart: func() {
+			update(func() { tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			update(func() { record.TLSHandshake = time.Since(tlsStart).String() })
+		},
+		WroteHeaderField: func(key string, value []string) {
+			switch key {
+			case "Authorization":
+				// The value is a credential and never recorded.
+				update(func() { record.Authorized = true })
+			case "Referer":
+				// The HTTP client only sets it when following a
+				// redirect.
+				if len(value) > 0 {
+					update(func() { record.RedirectedFrom = traceURL(value[0]) })
+				}
+			}
+		},
+		GotFirstResponseByte: func() {
+			lock.Lock()
+			defer lock.Unlock()
+			if record != nil {
+				record.FirstByte = time.Since(start).String()
+				finish()
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, clientTrace), func() {
+		lock.Lock()
+		defer lock.Unlock()
+		finish()
+	}
+}
+
+// traceReference is an image reference whose image source traces the HTTP
+// requests made by it.
+type traceReference struct {
+	types.ImageReference
+	tracer *pullTracer
+}
+
+func (r traceReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	image := r.DockerReference().String()
+	ctx, done := r.tracer.trace(ctx, image, "open", "")
+	start := time.Now()
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	done()
+	r.tracer.operation(image, "open", "", start, -1, 0, err)
+	if err != nil {
+		return nil, err
+	}
+	return &traceSource{ImageSource: src, tracer: r.tracer, image: image}, nil
+}
+
+// traceSource traces the HTTP requests made to read manifests, blobs and
+// signatures.
+type traceSource struct {
+	types.ImageSource
+	tracer *pullTracer
+	image  string
+}
+
+func (s *traceSource) GetManifest(ctx context.Context, instanceDigest *digest.Digest) ([]byte, string, error) {
+	var instance digest.Digest
+	if instanceDigest != nil {
+		instance = *instanceDigest
+	}
+	ctx, done := s.tracer.trace(ctx, s.image, "manifest", instance)
+	start := time.Now()
+	manifest, manifestType, err := s.ImageSource.GetManifest(ctx, instanceDigest)
+	done()
+	s.tracer.operation(s.image, "manifest", instance, start, -1, int64(len(manifest)), err)
+	return manifest, manifestType, err
+}
+
+func (s *traceSource) GetBlob(ctx context.Context, info types.BlobInfo, cache types.BlobInfoCache) (io.ReadCloser, int64, error) {
+	ctx, done := s.tracer.trace(ctx, s.image, "blob", info.Digest)
+	start := time.Now()
+	rc, size, err := s.ImageSource.GetBlob(ctx, info, cache)
+	if err != nil {
+		done()
+		s.tracer.operation(s.image, "blob", info.Digest, start, -1, 0, err)
+		return nil, 0, err
+	}
+	// Reading the blob can make more requests, e.g. when retrying, so
+	// the operation ends when it is closed.
+	return &traceReader{ReadCloser: rc, source: s, digest: info.Digest, start: start, done: done, size: size}, size, nil
+}
+
+func (s *traceSource) GetSignatures(ctx context.Context, instanceDigest *digest.Digest) ([][]byte, error) {
+	var instance digest.Digest
+	if instanceDigest != nil {
+		instance = *instanceDigest
+	}
+	ctx, done := s.tracer.trace(ctx, s.image, "signatures", instance)
+	start := time.Now()
+	signatures, err := s.ImageSource.GetSignatures(ctx, instanceDigest)
+	done()
+	s.tracer.operation(s.image, "signatures", instance, start, -1, int64(len(signatures)), err)
+	return signatures, err
+}
+
+// traceReader counts the bytes read from a blob and writes the record of
+// the operation when it is closed.
+type traceReader struct {
+	io.ReadCloser
+	source *traceSource
+	digest digest.Digest
+	start  time.Time
+	done   func()
+	// size is the length of the blob announced by the registry, -1 if
+	// unknown
+	size int64
+	read int64
+	err  error
+}
+
+func (r *traceReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *traceReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.done()
+	r.source.tracer.operation(r.source.image, "blob", r.digest, r.start, r.size, r.read, r.err)
+	return err
+}
@@ -19,11 +19,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func (ic *ContainerEngine) Info(ctx context.Context) (*define.Info, error) {
+func (ic *ContainerEngine) Info(ctx context.Context, options entities.SystemInfoOptions) (*define.Info, error) {
 	info, err := ic.Libpod.Info()
 	if err != nil {
 		return nil, err
 	}
+	if options.Detail && info.Host.CPUUtilization != nil {
+		perCPU, err := ic.Libpod.PerCPUUtilization()
+		if err != nil {
+			return nil, err
+		}
+		info.Host.CPUUtilization.PerCPU = perCPU
+	}
 	info.Host.RemoteSocket = &define.RemoteSocket{Path: ic.Libpod.RemoteURI()}
 
 	// `podman system connection add` invokes podman via ssh to fill in connection string. Here
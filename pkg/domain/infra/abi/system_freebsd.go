@@ -7,6 +7,9 @@ import (
 // Default path for system runtime state
 const defaultRunPath = "/var/run"
 
+// Directory of the system configuration
+const etcDir = "/usr/local/etc"
+
 // SetupRootless in a NOP for freebsd as it only configures the rootless userns on linux.
 func (ic *ContainerEngine) SetupRootless(_ context.Context, noMoveProcess bool, cgroupMode string) error {
 	return nil
@@ -17,6 +17,9 @@ import (
 // Default path for system runtime state
 const defaultRunPath = "/run"
 
+// Directory of the system configuration
+const etcDir = "/etc"
+
 func (ic *ContainerEngine) SetupRootless(_ context.Context, noMoveProcess bool, cgroupMode string) error {
 	runsUnderSystemd := systemd.RunsOnSystemd()
 	if !runsUnderSystemd {
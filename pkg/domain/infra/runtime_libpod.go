@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -124,6 +125,17 @@ func getRuntime(ctx context.Context, fs *flag.FlagSet, opts *engineOpts) (*libpo
 			storageOpts.GraphDriverOptions = cfg.StorageOpts
 		}
 	}
+	// Command specific overrides are applied on top of all of the above
+	if storeOptFlag := fs.Lookup("store-opt"); storeOptFlag != nil && storeOptFlag.Changed {
+		storeOpts, err := fs.GetStringArray("store-opt")
+		if err != nil {
+			return nil, err
+		}
+		if err := applyStoreOpts(&storageOpts, storeOpts); err != nil {
+			return nil, err
+		}
+		storageSet = true
+	}
 	if fs.Changed("transient-store") {
 		options = append(options, libpod.WithTransientStore(cfg.TransientStore))
 	}
@@ -325,3 +337,90 @@ func StartWatcher(rt *libpod.Runtime) {
 
 	logrus.Debugf("registered SIGHUP watcher for config")
 }
+
+// storeOptDrivers are the graph drivers --store-opt accepts options for.
+var storeOptDrivers = []string{"overlay", "overlay2", "vfs", "btrfs", "zfs", "aufs"}
+
+// applyStoreOpts applies the KEY=VALUE storage overrides of --store-opt to
+// storageOpts.  The "driver" key selects the graph driver, which drops the
+// configured driver options just like --storage-driver does.  All other keys
+// must be options of the graph driver in the form DRIVER.OPTION and replace a
+// configured option with the same key.
+func applyStoreOpts(storageOpts *types.StoreOptions, storeOpts []string) error {
+	driver := storageOpts.GraphDriverName
+	driverOpts := storageOpts.GraphDriverOptions
+	overrides := []string{}
+	for _, opt := range storeOpts {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid store option %q: must be in the form KEY=VALUE", opt)
+		}
+		if key != "driver" {
+			overrides = append(overrides, opt)
+			continue
+		}
+		if !slices.Contains(storeOptDrivers, value) {
+			return fmt.Errorf("invalid store option %q: unsupported graph driver %q", opt, value)
+		}
+		driver = value
+		driverOpts = []string{}
+	}
+
+	if driver == "" || driverOpts == nil {
+		defaults, err := types.DefaultStoreOptions()
+		if err != nil {
+			return err
+		}
+		if driver == "" {
+			driver = defaults.GraphDriverName
+		}
+		if driverOpts == nil {
+			driverOpts = defaults.GraphDriverOptions
+		}
+	}
+
+	// Do not modify the slice of the configuration
+	merged := slices.Clone(driverOpts)
+	if merged == nil {
+		merged = []string{}
+	}
+	for _, opt := range overrides {
+		key, _, _ := strings.Cut(opt, "=")
+		prefix, name, ok := strings.Cut(key, ".")
+		if !ok || name == "" || !slices.Contains(storeOptDrivers, prefix) {
+			return fmt.Errorf("invalid store option %q: key must be \"driver\" or in the form DRIVER.OPTION", opt)
+		}
+		if driver != "" && storeOptDriver(prefix) != storeOptDriver(driver) {
+			return fmt.Errorf("invalid store option %q: not an option of the %q graph driver", opt, driver)
+		}
+		merged = slices.DeleteFunc(merged, func(o string) bool {
+			k, _, _ := strings.Cut(o, "=")
+			return storeOptName(k) == strings.ToLower(name)
+		})
+		merged = append(merged, opt)
+	}
+
+	storageOpts.GraphDriverName = driver
+	storageOpts.GraphDriverOptions = merged
+	return nil
+}
+
+// storeOptDriver returns the canonical name of a graph driver.
+func storeOptDriver(driver string) string {
+	if driver == "overlay2" {
+		return "overlay"
+	}
+	return driver
+}
+
+// storeOptName returns the option name of a graph driver option key, which
+// may or may not be prefixed with the driver name.
+func storeOptName(key string) string {
+	key = strings.ToLower(strings.TrimPrefix(key, "."))
+	for _, driver := range storeOptDrivers {
+		if name, ok := strings.CutPrefix(key, driver+"."); ok {
+			return name
+		}
+	}
+	return key
+}
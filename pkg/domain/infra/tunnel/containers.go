@@ -463,7 +463,7 @@ func (ic *ContainerEngine) ContainerRestore(ctx context.Context, namesOrIds []st
 		}
 	} else {
 		getImageOptions := new(images.GetOptions).WithSize(false)
-		hostInfo, err := ic.Info(context.Background())
+		hostInfo, err := ic.Info(context.Background(), entities.SystemInfoOptions{})
 		if err != nil {
 			return nil, err
 		}
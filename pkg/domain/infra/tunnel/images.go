@@ -110,6 +110,90 @@ func (ir *ImageEngine) Pull(ctx context.Context, rawImage string, opts entities.
 	if opts.OciDecryptConfig != nil {
 		return nil, fmt.Errorf("decryption is not supported for remote clients")
 	}
+	if opts.CollectWarnings {
+		return nil, fmt.Errorf("collecting pull warnings is not supported for remote clients")
+	}
+	if opts.ManifestOnly {
+		return nil, fmt.Errorf("printing manifests is not supported for remote clients")
+	}
+	if opts.BlobCache != "" {
+		return nil, fmt.Errorf("blob caches are not supported for remote clients")
+	}
+	if opts.ReportReplaced {
+		return nil, fmt.Errorf("reporting replaced images is not supported for remote clients")
+	}
+	if opts.AcceptSchema1 {
+		return nil, fmt.Errorf("accepting schema 1 manifests is not supported for remote clients")
+	}
+	if opts.Progress != nil {
+		return nil, fmt.Errorf("progress events are not supported for remote clients")
+	}
+	if len(opts.PrefetchReferrers) > 0 {
+		return nil, fmt.Errorf("prefetching referrers is not supported for remote clients")
+	}
+	if opts.MaxLayerRetries > 0 {
+		return nil, fmt.Errorf("retrying single layers is not supported for remote clients")
+	}
+	if len(opts.LocalAnnotations) > 0 {
+		return nil, fmt.Errorf("annotating images is not supported for remote clients")
+	}
+	if opts.ValidateTar {
+		return nil, fmt.Errorf("validating layers is not supported for remote clients")
+	}
+	if opts.MinFreeSpace > 0 {
+		return nil, fmt.Errorf("requiring free space is not supported for remote clients")
+	}
+	if opts.DigestAlgorithm != "" {
+		return nil, fmt.Errorf("selecting the digest algorithm is not supported for remote clients")
+	}
+	if opts.TokenCacheDir != "" {
+		return nil, fmt.Errorf("caching tokens is not supported for remote clients")
+	}
+	if opts.RedirectAuthTo != "" {
+		return nil, fmt.Errorf("redirecting authentication is not supported for remote clients")
+	}
+	if opts.MirrorOnly {
+		return nil, fmt.Errorf("pulling only from mirrors is not supported for remote clients")
+	}
+	if opts.AcceptGzipOnly {
+		return nil, fmt.Errorf("accepting only gzip-compressed layers is not supported for remote clients")
+	}
+	if opts.ReuseBlobsFrom != "" {
+		return nil, fmt.Errorf("reusing blobs of another image is not supported for remote clients")
+	}
+	if opts.TraceFile != "" {
+		return nil, fmt.Errorf("tracing requests is not supported for remote clients")
+	}
+	if len(opts.PreferPlatformVariants) > 0 {
+		return nil, fmt.Errorf("preferring platform variants is not supported for remote clients")
+	}
+	if opts.CheckpointCompatible {
+		return nil, fmt.Errorf("checking checkpoint compatibility is not supported for remote clients")
+	}
+	if opts.StrictPlatform {
+		return nil, fmt.Errorf("strict platform matching is not supported for remote clients")
+	}
+	if opts.RecordToDB {
+		return nil, fmt.Errorf("recording pulls in the event log is not supported for remote clients")
+	}
+	if opts.NormalizeName {
+		return nil, fmt.Errorf("normalizing names is not supported for remote clients")
+	}
+	if len(opts.VerifyLayers) > 0 {
+		return nil, fmt.Errorf("verifying layers is not supported for remote clients")
+	}
+	if opts.FIPS {
+		return nil, fmt.Errorf("enforcing FIPS-compliant pulls is not supported for remote clients")
+	}
+	if opts.RepairLayers {
+		return nil, fmt.Errorf("repairing missing layers is not supported for remote clients")
+	}
+	if opts.WaitForRegistry > 0 {
+		return nil, fmt.Errorf("waiting for the registry is not supported for remote clients")
+	}
+	if !opts.AllTagsSince.IsZero() {
+		return nil, fmt.Errorf("filtering tags by creation time is not supported for remote clients")
+	}
 
 	options := new(images.PullOptions)
 	options.WithAllTags(opts.AllTags).WithAuthfile(opts.Authfile).WithArch(opts.Arch).WithOS(opts.OS)
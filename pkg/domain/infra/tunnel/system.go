@@ -9,8 +9,9 @@ import (
 	"github.com/containers/podman/v5/pkg/domain/entities"
 )
 
-func (ic *ContainerEngine) Info(ctx context.Context) (*define.Info, error) {
-	return system.Info(ic.ClientCtx, nil)
+func (ic *ContainerEngine) Info(ctx context.Context, opts entities.SystemInfoOptions) (*define.Info, error) {
+	options := new(system.InfoOptions).WithDetail(opts.Detail)
+	return system.Info(ic.ClientCtx, options)
 }
 
 func (ic *ContainerEngine) SetupRootless(_ context.Context, noMoveProcess bool, cgroupMode string) error {
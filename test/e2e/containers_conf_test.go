@@ -513,6 +513,11 @@ var _ = Describe("Verify podman containers.conf usage", func() {
 		Expect(session).Should(ExitCleanly())
 		Expect(session.OutputToString()).To(Equal("/foobar"))
 
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Store.ImageCopyTmpDirSource}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("config"))
+
 		containersConf = []byte(fmt.Sprintf("[engine]\nimage_copy_tmp_dir=%q", storagePath))
 		err = os.WriteFile(configPath, containersConf, os.ModePerm)
 		Expect(err).ToNot(HaveOccurred())
@@ -539,6 +544,11 @@ var _ = Describe("Verify podman containers.conf usage", func() {
 			session.WaitWithDefaultTimeout()
 			Expect(session).Should(ExitCleanly())
 			Expect(session.OutputToString()).To(Equal("/hoge"))
+
+			session = podmanTest.Podman([]string{"info", "--format", "{{.Store.ImageCopyTmpDirSource}}"})
+			session.WaitWithDefaultTimeout()
+			Expect(session).Should(ExitCleanly())
+			Expect(session.OutputToString()).To(Equal("env"))
 			os.Unsetenv("TMPDIR")
 		}
 	})
@@ -685,4 +695,105 @@ var _ = Describe("Verify podman containers.conf usage", func() {
 			Expect(inspect.OutputToString()).Should(Equal(mode))
 		}
 	})
+
+	It("podman info container defaults", func() {
+		// containers.conf is set to "nofile=500:500"
+		session := podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.Ulimits}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("[nofile=500:500]"))
+	})
+
+	It("podman info image volume mode", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.ImageVolumeMode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("anonymous"))
+
+		conffile := filepath.Join(podmanTest.TempDir, "container.conf")
+		err := os.WriteFile(conffile, []byte("[engine]\nimage_volume_mode = \"tmpfs\"\n"), 0755)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", conffile)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.ImageVolumeMode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("tmpfs"))
+	})
+
+	It("podman info default network mode", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.NetworkMode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		if isRootless() {
+			Expect(session.OutputToString()).To(Or(Equal("pasta"), Equal("slirp4netns")))
+		} else {
+			Expect(session.OutputToString()).To(Equal("bridge"))
+		}
+
+		conffile := filepath.Join(podmanTest.TempDir, "container.conf")
+		err := os.WriteFile(conffile, []byte("[containers]\nnetns = \"none\"\n"), 0755)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", conffile)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.NetworkMode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("none"))
+	})
+
+	It("podman info max parallel downloads", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Pull.MaxParallelDownloads}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("6"))
+
+		conffile := filepath.Join(podmanTest.TempDir, "container.conf")
+		err := os.WriteFile(conffile, []byte("[engine]\nimage_parallel_copies = 2\n"), 0755)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", conffile)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Pull.MaxParallelDownloads}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("2"))
+	})
+
+	It("podman info default sysctls", func() {
+		// containers.conf is set to "net.ipv4.ping_group_range=0 1000"
+		session := podmanTest.Podman([]string{"info", "--format", `{{index .ContainerDefaults.DefaultSysctls "net.ipv4.ping_group_range"}}`})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("0 1000"))
+	})
+
+	It("podman info timezone", func() {
+		// containers.conf is set to tz = "Pacific/Honolulu"
+		session := podmanTest.Podman([]string{"info", "--format", "{{.ContainerDefaults.TZ}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("Pacific/Honolulu"))
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Host.Timezone}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).ToNot(BeEmpty())
+	})
+
+	It("podman info volume plugins", func() {
+		// containers.conf sets volume_plugin_timeout = 15
+		session := podmanTest.Podman([]string{"info", "--format", `{{range .Plugins.VolumePlugins}}{{if eq .Name "testvol0"}}{{.Path}} {{.Timeout}}{{end}}{{end}}`})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("/run/docker/plugins/testvol0.sock 15"))
+	})
 })
@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 
 	. "github.com/containers/podman/v5/test/utils"
+	"github.com/containers/podman/v5/version"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gexec"
@@ -165,6 +167,486 @@ var _ = Describe("Podman Info", func() {
 		Expect(session.OutputToString()).To(Equal("netavark"))
 	})
 
+	It("Podman info: check helper binaries", func() {
+		session := podmanTest.Podman([]string{"info", "--format", `{{range .Host.HelperBinaries}}{{if eq .Name "netavark"}}{{.Path}}{{end}}{{end}}`})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveSuffix("/netavark"))
+	})
+
+	It("Podman info: per-CPU utilization only with --detail", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{len .Host.CPUUtilization.PerCPU}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("0"))
+
+		session = podmanTest.Podman([]string{"info", "--detail", "--format", "{{len .Host.CPUUtilization.PerCPU}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).ToNot(Equal("0"))
+	})
+
+	It("Podman info: check idmapped mounts support", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.IDMappedMountsSupported}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if isRootless() {
+			Expect(session.OutputToString()).To(Equal("false"))
+		} else {
+			Expect(session.OutputToString()).To(BeElementOf("true", "false"))
+		}
+	})
+
+	It("Podman info: check OCI runtime capabilities", func() {
+		// crun and runc are listed in the default runtime_supports_json, but not in runtime_supports_kvm
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.OCIRuntime.SupportsJSON}} {{.Host.OCIRuntime.SupportsKVM}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("true false"))
+	})
+
+	It("Podman info: check rootless port forwarder", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.RootlessNetworkCmd}} {{.Host.RootlessPortForwarder}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("pasta pasta", "slirp4netns rootlessport", "slirp4netns slirp4netns"))
+	})
+
+	It("Podman info: check compression format", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.CompressionFormat}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("gzip", "zstd", "zstd:chunked"))
+	})
+
+	It("Podman info: check FIPS mode", func() {
+		expected := "false"
+		if data, err := os.ReadFile("/proc/sys/crypto/fips_enabled"); err == nil && strings.TrimSpace(string(data)) == "1" {
+			expected = "true"
+		}
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.Security.FIPSEnabled}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(expected))
+	})
+
+	It("Podman info: check push compression defaults", func() {
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[engine]\ncompression_format = \"zstd\"\ncompression_level = 5\nadd_compression = [\"gzip\"]\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.CompressionFormat}} {{.Store.CompressionLevel}} {{.Store.AddCompression}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("zstd 5 [gzip]"))
+	})
+
+	It("Podman info: check CRIU", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.CRIU.Functional}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("true", "false"))
+	})
+
+	It("Podman info: check overlay mount program", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.GraphDriverName}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if session.OutputToString() != "overlay" {
+			Skip("test requires the overlay driver")
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", `{{.Store.NativeOverlayDiff}} {{with .Store.OverlayMountProgram}}{{.Executable}}{{else}}kernel{{end}}`})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Or(Equal("true kernel"), Equal("false kernel"), HavePrefix("false /")))
+	})
+
+	It("Podman info: check volatile overlay support", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.GraphDriverName}} {{.Store.VolatileOverlaySupported}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if strings.HasPrefix(session.OutputToString(), "overlay ") {
+			Expect(session.OutputToString()).To(BeElementOf("overlay true", "overlay false", "overlay <nil>"))
+		} else {
+			Expect(session.OutputToString()).To(HaveSuffix(" <nil>"))
+		}
+	})
+
+	It("Podman info: check registry config paths", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{index .RegistryConfigPaths 0}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(os.Getenv("CONTAINERS_REGISTRIES_CONF")))
+	})
+
+	It("Podman info: check user namespace mode", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.Security.UserNS.Mode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if isRootless() {
+			Expect(session.OutputToString()).To(Equal("rootless"))
+		} else {
+			Expect(session.OutputToString()).To(BeElementOf("host", "nested"))
+		}
+	})
+
+	It("Podman info: check mount usage", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.MountCount}} {{.Store.MountLimit}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		counts := strings.Fields(session.OutputToString())
+		Expect(counts).To(HaveLen(2))
+		Expect(counts[0]).ToNot(Equal("0"))
+		Expect(counts[1]).ToNot(Equal("0"))
+	})
+
+	It("Podman info: check rootless network helper sandbox", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.Pasta.Executable}} {{.Host.Pasta.Sandboxed}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		// pasta always sandboxes itself
+		if session.OutputToString() != "false" {
+			Expect(session.OutputToString()).To(HaveSuffix(" true"))
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Host.Slirp4NetNS.Sandboxed}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("true", "false"))
+	})
+
+	It("Podman info: check default capabilities list", func() {
+		session := podmanTest.Podman([]string{"info", "--format", `{{.Host.Security.DefaultCapabilities}}|{{join .Host.Security.DefaultCapabilitiesList ","}}|{{join .Host.Security.DroppedCapabilities ","}}`})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		caps := strings.Split(session.OutputToString(), "|")
+		Expect(caps).To(HaveLen(3))
+		Expect(caps[1]).To(Equal(caps[0]))
+		Expect(caps[1]).To(ContainSubstring("CAP_CHOWN"))
+		Expect(caps[2]).To(ContainSubstring("CAP_SYS_ADMIN"))
+		Expect(caps[2]).ToNot(ContainSubstring("CAP_CHOWN"))
+	})
+
+	It("Podman info: check additional image stores", func() {
+		// The test suite uses the image cache as additional image store.
+		session := podmanTest.Podman([]string{"info", "--format", "{{range .Store.AdditionalImageStores}}{{.Path}} {{.LayerStore}} {{.Images}}{{end}} {{.Store.ImageStore.AdditionalImages}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		fields := strings.Fields(session.OutputToString())
+		Expect(fields).To(HaveLen(4))
+		Expect(fields[0]).To(Equal(podmanTest.ImageCacheDir))
+		Expect(fields[1]).To(Equal(filepath.Join(podmanTest.ImageCacheDir, podmanTest.ImageCacheFS+"-layers")))
+		Expect(fields[2]).ToNot(Equal("0"))
+		Expect(fields[3]).To(Equal(fields[2]))
+	})
+
+	It("Podman info: check event log size", func() {
+		// The test suite uses the file event logger.
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.EventLogger}} {{.Host.EventLogMaxSize}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("file 1000000"))
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Host.EventLogFile}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveSuffix("events.log"))
+	})
+
+	It("Podman info: check transient store info", func() {
+		SkipIfRemote("--transient-store only applies to the local client")
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Store.TransientStoreInfo}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("<nil>"))
+
+		session = podmanTest.Podman([]string{"--transient-store", "info", "--format", "{{.Store.TransientStoreInfo.Path}}|{{.Store.TransientStoreInfo.DatabasePath}}|{{.Store.TransientStoreInfo.Description}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		fields := strings.Split(session.OutputToString(), "|")
+		Expect(fields).To(HaveLen(3))
+		Expect(fields[0]).To(Equal(podmanTest.RunRoot))
+		Expect(fields[1]).To(HavePrefix(podmanTest.TempDir))
+		Expect(fields[2]).To(ContainSubstring("Images are not affected."))
+	})
+
+	It("Podman info: check default mounts file", func() {
+		SkipIfRemote("--default-mounts-file only applies to the local client")
+		secretsDir := filepath.Join(podmanTest.TempDir, "rhel", "secrets")
+		err := os.MkdirAll(secretsDir, 0o755)
+		Expect(err).ToNot(HaveOccurred())
+		mountsFile := filepath.Join(podmanTest.TempDir, "mounts.conf")
+		err = os.WriteFile(mountsFile, []byte(secretsDir+":/run/secrets\n/does/not/exist:/run/missing\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+
+		session := podmanTest.Podman([]string{"--default-mounts-file=" + mountsFile, "info", "--format", "{{.Host.Security.DefaultMountsFile}} {{.Host.Security.DefaultMounts}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(mountsFile + " 1"))
+	})
+
+	It("Podman info: check network plugins", func() {
+		pluginDir := filepath.Join(podmanTest.TempDir, "plugins")
+		err := os.MkdirAll(pluginDir, 0o755)
+		Expect(err).ToNot(HaveOccurred())
+		plugin := filepath.Join(pluginDir, "test-plugin")
+		err = os.WriteFile(plugin, []byte("#!/bin/sh\n"), 0o755)
+		Expect(err).ToNot(HaveOccurred())
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		conf := fmt.Sprintf("[network]\ncni_plugin_dirs = [%q]\nnetavark_plugin_dirs = [%q]\n", pluginDir, pluginDir)
+		err = os.WriteFile(configPath, []byte(conf), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.NetworkPlugins.Dirs}} {{.Host.NetworkPlugins.Plugins}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(fmt.Sprintf("[%s] [%s]", pluginDir, plugin)))
+	})
+
+	It("Podman info: check storage warnings", func() {
+		SkipIfRemote("--root and --runroot only apply to the local client")
+		root := filepath.Join(podmanTest.TempDir, "warnings-root")
+		runroot := filepath.Join(podmanTest.TempDir, "warnings-runroot")
+		session := podmanTest.Podman([]string{"--root", root, "--runroot", runroot, "info", "--format", "{{range .Store.StorageWarnings}}{{.}}\n{{end}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToStringArray()).To(ContainElement(fmt.Sprintf("runRoot %s and graphRoot %s share a file system, runtime state and images compete for space on it", runroot, root)))
+	})
+
+	It("Podman info: check pids", func() {
+		pidMax, err := os.ReadFile("/proc/sys/kernel/pid_max")
+		Expect(err).ToNot(HaveOccurred())
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.Pids.PidMax}} {{.Host.Pids.InUse}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		fields := strings.Fields(session.OutputToString())
+		Expect(fields).To(HaveLen(2))
+		Expect(fields[0]).To(Equal(strings.TrimSpace(string(pidMax))))
+		inUse, err := strconv.Atoi(fields[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inUse).To(BeNumerically(">", 0))
+	})
+
+	It("Podman info: check hooks", func() {
+		SkipIfRemote("--hooks-dir only applies to the local client")
+		lowDir := filepath.Join(podmanTest.TempDir, "hooks-low")
+		highDir := filepath.Join(podmanTest.TempDir, "hooks-high")
+		hook := []byte(`{"version":"1.0.0","hook":{"path":"/bin/sh"},"when":{"always":true},"stages":["prestart"]}`)
+		for _, dir := range []string{lowDir, highDir} {
+			err := os.MkdirAll(dir, 0o755)
+			Expect(err).ToNot(HaveOccurred())
+			err = os.WriteFile(filepath.Join(dir, "test.json"), hook, 0o644)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		session := podmanTest.Podman([]string{"--hooks-dir", lowDir, "--hooks-dir", highDir, "info", "--format", "{{range .Host.Hooks.Hooks}}{{.Path}} {{.Overrides}}{{end}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(fmt.Sprintf("%s [%s]", filepath.Join(highDir, "test.json"), filepath.Join(lowDir, "test.json"))))
+	})
+
+	It("Podman info: check no_pivot_root", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.OCIRuntime.NoPivotRoot}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("false"))
+
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[engine]\nno_pivot_root = true\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Host.OCIRuntime.NoPivotRoot}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("true"))
+	})
+
+	It("Podman info: check service runtime", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{if .ServiceRuntime}}{{.ServiceRuntime.Goroutines}} {{.ServiceRuntime.OpenFDs}}{{end}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if !IsRemote() {
+			// Only the service reports its resource usage.
+			Expect(session.OutputToString()).To(BeEmpty())
+			return
+		}
+		fields := strings.Fields(session.OutputToString())
+		Expect(fields).To(HaveLen(2))
+		goroutines, err := strconv.Atoi(fields[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(goroutines).To(BeNumerically(">", 0))
+		fds, err := strconv.Atoi(fields[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fds).To(BeNumerically(">", 0))
+	})
+
+	It("Podman info: check rootless network command source", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.RootlessNetworkCmd}} {{.Host.RootlessNetworkCmdSource}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("pasta default", "slirp4netns config"))
+
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[network]\ndefault_rootless_network_cmd = \"slirp4netns\"\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Host.RootlessNetworkCmd}} {{.Host.RootlessNetworkCmdSource}}|{{.Host.RootlessNetworkCmdReason}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(HavePrefix(`slirp4netns config|default_rootless_network_cmd is set to "slirp4netns" in containers.conf`))
+	})
+
+	It("Podman info: check open file limit", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.RLimitNofileSoft}} {{.Host.RLimitNofileHard}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		fields := strings.Fields(session.OutputToString())
+		Expect(fields).To(HaveLen(2))
+		soft, err := strconv.ParseUint(fields[0], 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+		hard, err := strconv.ParseUint(fields[1], 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(soft).To(BeNumerically(">", 0))
+		Expect(soft).To(BeNumerically("<=", hard))
+	})
+
+	It("Podman info: check security defaults", func() {
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[containers]\ndefault_capabilities = [\"CHOWN\", \"KILL\"]\ndefault_ulimits = [\"nproc=2048:4096\"]\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session := podmanTest.Podman([]string{"info", "--format", "{{.SecurityDefaults.AddedCapabilities}} {{.SecurityDefaults.RemovedCapabilities}} {{range .SecurityDefaults.Ulimits}}{{.Name}}={{.OCI}}/{{.Podman}} {{end}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("[CAP_CHOWN] [CAP_AUDIT_WRITE CAP_NET_BIND_SERVICE] nofile=1024:1024/inherited nproc=/2048:4096"))
+	})
+
+	It("Podman info: check container DNS defaults", func() {
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[containers]\ndns_servers = [\"1.1.1.1\", \"9.9.9.9\"]\ndns_searches = [\"example.com\"]\ndns_options = [\"ndots:2\"]\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session := podmanTest.Podman([]string{"info", "--format", "{{.ContainerDNS.Servers}} {{.ContainerDNS.Searches}} {{.ContainerDNS.Options}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("[1.1.1.1 9.9.9.9] [example.com] [ndots:2]"))
+	})
+
+	It("Podman info: check store writer version", func() {
+		SkipIfRemote("the version file is written by the local store")
+		session := podmanTest.Podman([]string{"tag", ALPINE, "store-writer-test"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Store.LastWriterVersion}} {{.Store.LastWriterVersionMismatch}} {{.Store.Locked}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(version.Version.String() + " false false"))
+	})
+
+	It("Podman info: check lock backend", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Locks.Type}} {{.Locks.Path}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Or(HavePrefix("shm /dev/shm/libpod_"), HavePrefix("file /")))
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{with .Locks.Acquisitions}}{{.}}{{else}}none{{end}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		if IsRemote() {
+			Expect(session.OutputToString()).To(MatchRegexp(`^\d+$`))
+		} else {
+			Expect(session.OutputToString()).To(Equal("none"))
+		}
+	})
+
+	It("Podman info: check cgroup hybrid", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Host.CgroupsVersion}} {{.Host.CgroupHybrid}}"})
+		session.WaitWithDefaultTimeout()
+		// Hybrid setups warn on stderr.
+		Expect(session).To(Exit(0))
+		if CGROUPSV2 {
+			Expect(session.OutputToString()).To(Equal("v2 false"))
+		} else {
+			Expect(session.OutputToString()).To(BeElementOf("v1 false", "v1 true"))
+		}
+	})
+
+	It("Podman info: check machine", func() {
+		// The tests never connect to a podman machine.
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Machine}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("<nil>"))
+	})
+
+	It("Podman info: check short-name mode", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Pull.ShortNameMode}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(BeElementOf("enforcing", "permissive", "disabled"))
+	})
+
+	It("Podman info: check default transport", func() {
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[engine]\nimage_default_transport = \"oci-archive:\"\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Pull.DefaultTransport}} {{.Pull.ImageDefaultTransport}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("docker:// oci-archive:"))
+	})
+
+	It("Podman info: check Docker Hub enforcement of the compat API", func() {
+		session := podmanTest.Podman([]string{"info", "--format", "{{.Pull.CompatAPIEnforceDockerHub}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("true"))
+
+		configPath := filepath.Join(podmanTest.TempDir, "containers.conf")
+		err := os.WriteFile(configPath, []byte("[engine]\ncompat_api_enforce_docker_hub = false\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("CONTAINERS_CONF_OVERRIDE", configPath)
+		if IsRemote() {
+			podmanTest.RestartRemoteService()
+		}
+
+		session = podmanTest.Podman([]string{"info", "--format", "{{.Pull.CompatAPIEnforceDockerHub}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).To(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("false"))
+	})
+
 	It("Podman info: check desired database backend", func() {
 		// defined in .cirrus.yml
 		want := os.Getenv("CI_DESIRED_DATABASE")
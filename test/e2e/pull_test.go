@@ -1,11 +1,16 @@
 package integration
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"time"
 
+	"github.com/containers/podman/v5/pkg/domain/entities"
 	. "github.com/containers/podman/v5/test/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -28,6 +33,77 @@ var _ = Describe("Podman pull", func() {
 		Expect(session).Should(ExitCleanly())
 	})
 
+	It("podman pull --concurrent-images", func() {
+		session := podmanTest.Podman([]string{"pull", "-q", "--concurrent-images", "2", "busybox:musl", "quay.io/libpod/cirros", "docker.io/library/ibetthisdoesnotexistfr:random"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "initializing source docker://ibetthisdoesnotexistfr:random"))
+		Expect(session.OutputToStringArray()).To(HaveLen(2))
+
+		session = podmanTest.Podman([]string{"pull", "--concurrent-images", "0", "busybox:musl"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--concurrent-images must be at least 1"))
+
+		if !IsRemote() {
+			session = podmanTest.Podman([]string{"pull", "--concurrent-images", "2", "--fail-on-warning", "busybox:musl"})
+			session.WaitWithDefaultTimeout()
+			Expect(session).Should(ExitWithError(125, "--fail-on-warning option can not be specified with --concurrent-images greater than 1"))
+		}
+
+		session = podmanTest.Podman([]string{"rmi", "busybox:musl", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+	})
+
+	It("podman pull --stop-on-error", func() {
+		session := podmanTest.Podman([]string{"pull", "-q", "--stop-on-error", "docker.io/library/ibetthisdoesnotexistfr:random", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "initializing source docker://ibetthisdoesnotexistfr:random"))
+		Expect(session.OutputToString()).To(BeEmpty())
+
+		session = podmanTest.Podman([]string{"image", "exists", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(1, ""))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--no-keep-going", "docker.io/library/ibetthisdoesnotexistfr:random", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "initializing source docker://ibetthisdoesnotexistfr:random"))
+		Expect(session.OutputToString()).To(BeEmpty())
+	})
+
+	It("podman pull --progress", func() {
+		session := podmanTest.Podman([]string{"pull", "--progress", "none", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.ErrorToString()).To(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "--progress", "ascii", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(ContainSubstring("Copying blob "))
+		Expect(session.ErrorToString()).ToNot(ContainSubstring("\x1b["))
+
+		session = podmanTest.Podman([]string{"pull", "--progress-bars", "ascii", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "unknown flag: --progress-bars"))
+
+		session = podmanTest.Podman([]string{"pull", "--quiet", "--progress", "plain", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--quiet option can not be specified with --progress plain"))
+
+		session = podmanTest.Podman([]string{"pull", "--progress", "bogus", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid progress mode "bogus"`))
+
+		session = podmanTest.Podman([]string{"pull", "--progress", "json", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		if IsRemote() {
+			Expect(session).Should(ExitWithError(125, "--progress json is not supported for remote clients"))
+		} else {
+			Expect(session).Should(Exit(0))
+			Expect(session.ErrorToString()).To(ContainSubstring(`"status":"Download complete"`))
+		}
+	})
+
 	It("podman pull bogus image", func() {
 		// This is a NOP in CI; but in a developer environment, if user
 		// has a valid login to quay.io, pull fails with "repository not found"
@@ -57,6 +133,638 @@ var _ = Describe("Podman pull", func() {
 		Expect(session).Should(ExitCleanly())
 	})
 
+	It("podman pull --print-manifest", func() {
+		SkipIfRemote("--print-manifest is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "--print-manifest", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(ContainSubstring(`"schemaVersion"`))
+
+		session = podmanTest.Podman([]string{"image", "exists", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(1, ""))
+	})
+
+	It("podman pull --accept-schema1", func() {
+		SkipIfRemote("--accept-schema1 is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "quay.io/libpod/testdigest_v2s1:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "only provides a deprecated Docker schema 1 manifest, use --accept-schema1 to pull it anyway"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--accept-schema1", "quay.io/libpod/testdigest_v2s1:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(ContainSubstring("deprecated Docker schema 1 manifest"))
+	})
+
+	It("podman pull --blob-cache", func() {
+		SkipIfRemote("--blob-cache is not supported on the remote client")
+		cacheDir := filepath.Join(podmanTest.TempDir, "blobcache")
+		session := podmanTest.Podman([]string{"pull", "-q", "--blob-cache", cacheDir, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		entries, err := os.ReadDir(cacheDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).ToNot(BeEmpty())
+
+		session = podmanTest.Podman([]string{"rmi", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--blob-cache", cacheDir, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+	})
+
+	It("podman pull --store-opt", func() {
+		SkipIfRemote("--store-opt is not supported on the remote client")
+		root := filepath.Join(podmanTest.TempDir, "store-opt-root")
+		runroot := filepath.Join(podmanTest.TempDir, "store-opt-runroot")
+		session := podmanTest.Podman([]string{"--root", root, "--runroot", runroot, "pull", "-q", "--store-opt", "driver=vfs", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"--root", root, "--runroot", runroot, "info", "--format", "{{.Store.GraphDriverName}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal("vfs"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--store-opt", "mountopt", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid store option "mountopt": must be in the form KEY=VALUE`))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--store-opt", "driver=vfs", "--store-opt", "overlay.mountopt=nodev", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid store option "overlay.mountopt=nodev": not an option of the "vfs" graph driver`))
+	})
+
+	It("podman pull --quiet-on-cache-hit", func() {
+		SkipIfRemote("--quiet-on-cache-hit is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--policy", "missing", "--quiet-on-cache-hit", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).ToNot(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "--policy", "missing", "--quiet-on-cache-hit", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(BeEmpty())
+		Expect(session.ErrorToString()).To(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--policy", "missing", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).ToNot(BeEmpty())
+	})
+
+	It("podman pull --exit-code-on-noop", func() {
+		SkipIfRemote("--exit-code-on-noop is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--policy", "missing", "--exit-code-on-noop", "3", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--policy", "missing", "--exit-code-on-noop", "3", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(3))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--exit-code-on-noop", "3", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+	})
+
+	It("podman pull --no-trunc-errors", func() {
+		session := podmanTest.Podman([]string{"pull", "-q", "--no-trunc-errors", "quay.io/libpod/ibetthisdoesnotexistfr:random"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "quay.io/libpod/ibetthisdoesnotexistfr:random: initializing source docker://quay.io/libpod/ibetthisdoesnotexistfr:random"))
+	})
+
+	It("podman pull --policy never", func() {
+		session := podmanTest.Podman([]string{"pull", "--policy", "never", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "image not known"))
+
+		session = podmanTest.Podman([]string{"pull", "--policy", "bogus", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `unsupported pull policy "bogus"`))
+	})
+
+	It("podman pull --all-tags --since-event", func() {
+		SkipIfRemote("--since-event is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--all-tags", "--since-event", "2100-01-01", "quay.io/libpod/testdigest_v2s2"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--since-event", "24h", "quay.io/libpod/testdigest_v2s2"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--since-event option can only be specified with --all-tags"))
+	})
+
+	It("podman pull --prefetch-referrers", func() {
+		SkipIfRemote("--prefetch-referrers is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--prefetch-referrers", "signature,attestation", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).ToNot(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--prefetch-referrers", "bogus", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid referrer type "bogus"`))
+	})
+
+	It("podman pull --registries-conf", func() {
+		SkipIfRemote("--registries-conf is not supported on the remote client")
+		blocked := filepath.Join(podmanTest.TempDir, "blocked.conf")
+		err := os.WriteFile(blocked, []byte("[[registry]]\nlocation=\"quay.io/libpod/testdigest_v2s2\"\nblocked=true\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		session := podmanTest.Podman([]string{"pull", "-q", "--registries-conf", blocked, "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "registry quay.io/libpod/testdigest_v2s2 is blocked in "+blocked))
+
+		malformed := filepath.Join(podmanTest.TempDir, "malformed.conf")
+		err = os.WriteFile(malformed, []byte("[[registry]\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		session = podmanTest.Podman([]string{"pull", "-q", "--registries-conf", malformed, "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, fmt.Sprintf("invalid --registries-conf: loading registries configuration %q", malformed)))
+	})
+
+	It("podman pull --digest-algorithm", func() {
+		SkipIfRemote("--digest-algorithm is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "--digest-algorithm", "sha512", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(ContainSubstring("does not provide sha512 digests, falling back to sha256"))
+		Expect(session.ErrorToString()).To(MatchRegexp(`Blob sha256:[0-9a-f]{64} uses sha256`))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--digest-algorithm", "md5", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid digest algorithm "md5"`))
+	})
+
+	It("podman pull --min-free-space", func() {
+		SkipIfRemote("--min-free-space is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--min-free-space", "1PB", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "not enough free space on "))
+		Expect(session.ErrorToString()).To(ContainSubstring("at least 909.5TiB required"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--min-free-space", "1kB", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--min-free-space", "bogus", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `parsing --min-free-space "bogus"`))
+	})
+
+	It("podman pull --proxy", func() {
+		SkipIfRemote("--proxy is not supported on the remote client")
+		// Nothing listens on the discard port, so pulling through it fails.
+		session := podmanTest.Podman([]string{"pull", "-q", "--retry", "0", "--proxy", "http://127.0.0.1:9", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "proxyconnect tcp"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--log-level", "debug", "--proxy", "http://127.0.0.1:9", "--no-proxy", "quay.io", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(ContainSubstring("Pulling through proxy http://127.0.0.1:9"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--proxy", "ftp://127.0.0.1", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid --proxy "ftp://127.0.0.1": scheme must be http, https or socks5`))
+	})
+
+	It("podman pull --validate-tar", func() {
+		SkipIfRemote("--validate-tar is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--validate-tar", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveLen(64))
+	})
+
+	It("podman pull --annotate", func() {
+		SkipIfRemote("--annotate is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--annotate", "pulled-by=e2e", "--annotate", "reason=test=1", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--annotate", "reason=again", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		inspect := podmanTest.Podman([]string{"image", "inspect", "--format", `{{index .LocalAnnotations "pulled-by"}} {{index .LocalAnnotations "reason"}}`, "quay.io/libpod/testdigest_v2s2:20200210"})
+		inspect.WaitWithDefaultTimeout()
+		Expect(inspect).Should(ExitCleanly())
+		Expect(inspect.OutputToString()).To(Equal("e2e again"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--annotate", "=value", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid annotation "=value": must be in the form KEY=VALUE`))
+	})
+
+	It("podman pull --max-layer-retries", func() {
+		SkipIfRemote("--max-layer-retries is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--max-layer-retries", "3", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveLen(64))
+	})
+
+	It("podman pull --wait-for-registry", func() {
+		SkipIfRemote("--wait-for-registry is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--wait-for-registry", "60s", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveLen(64))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--tls-verify=false", "--wait-for-registry", "2s", "localhost:9/libpod/alpine"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "registry localhost:9 unreachable after 2s"))
+
+		session = podmanTest.Podman([]string{"pull", "--wait-for-registry", "soon", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `parsing --wait-for-registry "soon"`))
+	})
+
+	It("podman pull --mirror-only", func() {
+		SkipIfRemote("--mirror-only is not supported on the remote client")
+		conf := filepath.Join(podmanTest.TempDir, "registries.conf")
+		err := os.WriteFile(conf, []byte(`
+[[registry]]
+location = "example.invalid/libpod"
+
+[[registry.mirror]]
+location = "quay.io/libpod"
+`), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+
+		session := podmanTest.Podman([]string{"pull", "-q", "--mirror-only", "--registries-conf", conf, "example.invalid/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveLen(64))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--mirror-only", "--registries-conf", conf, "quay.io/libpod/alpine:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "no mirror is configured for quay.io/libpod/alpine:latest, pulling it would contact the upstream registry quay.io"))
+	})
+
+	It("podman pull --redirect-auth-to", func() {
+		SkipIfRemote("--redirect-auth-to is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--redirect-auth-to", "quay.io", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HaveLen(64))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--redirect-auth-to", "auth.invalid", "quay.io/libpod/alpine:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "redirecting authentication for quay.io/libpod/alpine:latest"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--redirect-auth-to", "https://quay.io", "quay.io/libpod/alpine:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, `invalid authentication host "https://quay.io"`))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--redirect-auth-to", "quay.io", "--cache-dir", podmanTest.TempDir, "quay.io/libpod/alpine:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--redirect-auth-to option can not be specified with --cache-dir"))
+	})
+
+	It("podman pull --json", func() {
+		session := podmanTest.Podman([]string{"pull", "-q", "--json", "quay.io/libpod/testdigest_v2s2:20200210", "quay.io/libpod/does-not-exist:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "quay.io/libpod/does-not-exist"))
+		var result entities.ImagePullResult
+		err := json.Unmarshal(session.Out.Contents(), &result)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Images).To(HaveLen(2))
+		Expect(result.Images[0].Reference).To(Equal("quay.io/libpod/testdigest_v2s2:20200210"))
+		Expect(result.Images[0].Status).To(Equal("pulled"))
+		Expect(result.Images[0].ID).To(HaveLen(64))
+		Expect(result.Images[0].Names).To(ContainElement("quay.io/libpod/testdigest_v2s2:20200210"))
+		Expect(result.Images[0].Digest).To(HavePrefix("sha256:"))
+		Expect(result.Images[0].Size).To(BeNumerically(">", 0))
+		Expect(result.Images[1].Status).To(Equal("failed"))
+		Expect(result.Images[1].Error).ToNot(BeEmpty())
+
+		session = podmanTest.Podman([]string{"pull", "--json", "--repo-digest-only", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--json option can not be specified with --repo-digest-only or --print-manifest"))
+	})
+
+	It("podman pull --cache-dir", func() {
+		SkipIfRemote("--cache-dir is not supported on the remote client")
+		cacheDir := filepath.Join(podmanTest.TempDir, "token-cache")
+		for i := 0; i < 2; i++ {
+			session := podmanTest.Podman([]string{"pull", "-q", "--cache-dir", cacheDir, "quay.io/libpod/testdigest_v2s2:20200210"})
+			session.WaitWithDefaultTimeout()
+			Expect(session).Should(ExitCleanly())
+		}
+		tokens, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tokens).To(HaveLen(1))
+		st, err := os.Stat(tokens[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(st.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+		err = os.Chmod(cacheDir, 0o755)
+		Expect(err).ToNot(HaveOccurred())
+		session := podmanTest.Podman([]string{"pull", "-q", "--cache-dir", cacheDir, "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "must not be accessible by other users"))
+	})
+
+	It("podman pull --accept-gzip-only", func() {
+		SkipIfRemote("--accept-gzip-only is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--accept-gzip-only", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"image", "exists", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+	})
+
+	It("podman pull --after-pull-exec", func() {
+		hookOut := filepath.Join(podmanTest.TempDir, "hook.out")
+		hook := fmt.Sprintf(`sh -c 'echo "$1 $PODMAN_PULL_REFERENCE $PODMAN_PULL_IMAGE_ID" > %s' sh`, hookOut)
+		session := podmanTest.Podman([]string{"pull", "-q", "--after-pull-exec", hook, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		content, err := os.ReadFile(hookOut)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal(fmt.Sprintf("%s %s %s\n", ALPINE, ALPINE, session.OutputToString())))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--after-pull-exec", "false", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, fmt.Sprintf("running --after-pull-exec for %s: exit status 1", ALPINE)))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--after-pull-exec", "false", "--ignore-hook-errors", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(ContainSubstring("running --after-pull-exec for %s: exit status 1", ALPINE))
+	})
+
+	It("podman pull --after-pull-exec does not get the proxy settings", func() {
+		SkipIfRemote("--no-proxy is not supported on the remote client")
+		hookOut := filepath.Join(podmanTest.TempDir, "hook.out")
+		hook := fmt.Sprintf(`sh -c 'echo "${NO_PROXY-unset}" > %s'`, hookOut)
+		session := podmanTest.Podman([]string{"pull", "-q", "--no-proxy", "example.invalid", "--after-pull-exec", hook, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		content, err := os.ReadFile(hookOut)
+		Expect(err).ToNot(HaveOccurred())
+		noProxy, ok := os.LookupEnv("NO_PROXY")
+		if !ok {
+			noProxy = "unset"
+		}
+		Expect(string(content)).To(Equal(noProxy + "\n"))
+	})
+
+	It("podman pull --reuse-blobs-from", func() {
+		SkipIfRemote("--reuse-blobs-from is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--reuse-blobs-from", "does-not-exist", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "looking up image to reuse blobs from: does-not-exist: image not known"))
+
+		session = podmanTest.Podman([]string{"pull", "-q", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		id := session.OutputToString()
+
+		session = podmanTest.Podman([]string{"rmi", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", BB})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--reuse-blobs-from", BB, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(Equal(id))
+	})
+
+	It("podman pull --trace", func() {
+		SkipIfRemote("--trace is not supported on the remote client")
+		traceFile := filepath.Join(podmanTest.TempDir, "trace.json")
+		session := podmanTest.Podman([]string{"pull", "-q", "--trace", traceFile, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		content, err := os.ReadFile(traceFile)
+		Expect(err).ToNot(HaveOccurred())
+		var blobs int
+		for _, line := range bytes.Split(bytes.TrimSpace(content), []byte("\n")) {
+			var record map[string]any
+			err := json.Unmarshal(line, &record)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(record).To(HaveKeyWithValue("image", ALPINE))
+			if record["type"] == "operation" && record["operation"] == "blob" {
+				Expect(record).ToNot(HaveKey("error"))
+				Expect(record).To(HaveKeyWithValue("statusCode", BeEquivalentTo(200)))
+				Expect(record).To(HaveKeyWithValue("contentLength", record["size"]))
+				blobs++
+			}
+		}
+		Expect(blobs).To(BeNumerically(">", 0))
+	})
+
+	It("podman pull --lock-dir", func() {
+		lockDir := filepath.Join(podmanTest.TempDir, "locks")
+		session := podmanTest.Podman([]string{"pull", "-q", "--lock-dir", lockDir, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		// The lock file is removed after the pull.
+		entries, err := os.ReadDir(lockDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("podman pull --prefer-platform-variant", func() {
+		SkipIfRemote("--prefer-platform-variant is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--arch=arm64", "--variant=v8", "--prefer-platform-variant", "v8", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--prefer-platform-variant option can not be specified with a variant in --variant or --platform"))
+
+		// Falls back to the default choice if no variant is available.
+		session = podmanTest.Podman([]string{"pull", "-q", "--arch=arm64", "--prefer-platform-variant", "v9,v8", ALPINELISTDIGEST})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"inspect", "--format", "{{.Architecture}} {{.RepoDigests}}", ALPINELISTDIGEST})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToString()).To(HavePrefix("arm64 "))
+		Expect(session.OutputToString()).To(ContainSubstring(ALPINEARM64DIGEST))
+	})
+
+	It("podman pull --checkpoint-compatible", func() {
+		SkipIfRemote("--checkpoint-compatible is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--checkpoint-compatible", "--arch=arm64", ALPINELISTDIGEST})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		if runtime.GOARCH != "arm64" {
+			Expect(session.ErrorToString()).To(ContainSubstring("but CRIU can only restore linux/" + runtime.GOARCH))
+		}
+	})
+
+	It("podman pull --strict-platform", func() {
+		SkipIfRemote("--strict-platform is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--strict-platform", "--platform=linux/arm64", ALPINELISTDIGEST})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--strict-platform", "--platform=linux/riscv64", ALPINEARM64DIGEST})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "does not provide the platform linux/riscv64, only linux/arm64"))
+	})
+
+	It("podman pull --record-to-db", func() {
+		SkipIfRemote("--record-to-db is not supported on the remote client")
+		start := time.Now()
+		session := podmanTest.Podman([]string{"pull", "-q", "--record-to-db", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		id := session.OutputToString()
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--record-to-db", "quay.io/libpod/does-not-exist:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "quay.io/libpod/does-not-exist"))
+
+		session = podmanTest.Podman([]string{"events", "--stream=false", "--since", strconv.FormatInt(start.Unix(), 10),
+			"--filter", "type=image", "--format", "{{.Status}} {{.ID}} {{.Attributes.result}} {{.Attributes.digest}}"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToStringArray()).To(ContainElement(MatchRegexp(`^pull-record %s (pulled|cached) sha256:[0-9a-f]{64}$`, id)))
+		Expect(session.OutputToStringArray()).To(ContainElement(HavePrefix("pull-record  failed ")))
+		// The events written by the pull itself carry no attributes.
+		Expect(session.OutputToStringArray()).ToNot(ContainElement(MatchRegexp(`^pull(-error)? \S* (pulled|cached|failed)`)))
+	})
+
+	It("podman pull --verify-layers", func() {
+		SkipIfRemote("--verify-layers is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		session = podmanTest.Podman([]string{"inspect", "--format", "{{range .RootFS.Layers}}{{println .}}{{end}}", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		layers := filepath.Join(podmanTest.TempDir, "layers")
+		err := os.WriteFile(layers, []byte("# alpine\n"+session.OutputToString()+"\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--verify-layers", layers, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		err = os.WriteFile(layers, []byte("sha256:0000000000000000000000000000000000000000000000000000000000000000\n"), 0o644)
+		Expect(err).ToNot(HaveOccurred())
+		session = podmanTest.Podman([]string{"pull", "-q", "--verify-layers", layers, ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "has diff ID"))
+	})
+
+	It("podman pull --fips", func() {
+		SkipIfRemote("--fips is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--fips", "--tls-verify=false", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "pulling without TLS verification is not allowed in FIPS mode"))
+	})
+
+	It("podman pull --download-only-missing-layers", func() {
+		SkipIfRemote("--download-only-missing-layers is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		id := session.OutputToString()
+
+		// All layers are present, so nothing is pulled or repaired.
+		session = podmanTest.Podman([]string{"pull", "--download-only-missing-layers", "--json", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		var result entities.ImagePullResult
+		Expect(json.Unmarshal(session.Out.Contents(), &result)).To(Succeed())
+		Expect(result.Images).To(HaveLen(1))
+		Expect(result.Images[0].ID).To(Equal(id))
+		Expect(result.Images[0].RepairedLayers).To(BeZero())
+
+		session = podmanTest.Podman([]string{"pull", "--download-only-missing-layers", "--policy", "always", ALPINE})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--download-only-missing-layers option can not be specified with --policy"))
+	})
+
+	It("podman pull --normalize-name", func() {
+		SkipIfRemote("--normalize-name is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "--normalize-name", "alpine"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		lines := session.OutputToStringArray()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(Equal(ALPINE))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--normalize-name", "--json", "alpine"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		var result entities.ImagePullResult
+		Expect(json.Unmarshal(session.Out.Contents(), &result)).To(Succeed())
+		Expect(result.Images).To(HaveLen(1))
+		Expect(result.Images[0].ResolvedName).To(Equal(ALPINE))
+		Expect(result.Images[0].ID).To(Equal(lines[1]))
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--normalize-name", "--all-tags", "alpine"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--normalize-name option can not be specified with --all-tags"))
+	})
+
+	It("podman pull --summary-only", func() {
+		SkipIfRemote("--summary-only is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--summary-only", "--policy", "missing", "quay.io/libpod/testdigest_v2s2:20200210", "quay.io/libpod/cirros", "quay.io/libpod/does-not-exist:latest"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "quay.io/libpod/does-not-exist"))
+		Expect(session.OutputToString()).To(Equal("Pulled 1 image, 1 already present, 1 failed"))
+
+		session = podmanTest.Podman([]string{"pull", "--summary-only", "--json", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitWithError(125, "--summary-only option can not be specified with --json, --repo-digest-only or --print-manifest"))
+	})
+
+	It("podman pull --overwrite", func() {
+		SkipIfRemote("--overwrite is not supported on the remote client")
+		session := podmanTest.Podman([]string{"pull", "-q", "quay.io/libpod/cirros"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		oldID := session.OutputToString()
+
+		session = podmanTest.Podman([]string{"tag", oldID, "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+
+		session = podmanTest.Podman([]string{"pull", "-q", "--overwrite", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(Exit(0))
+		Expect(session.ErrorToString()).To(Equal("Replaced image " + oldID))
+		Expect(session.OutputToString()).ToNot(Equal(oldID))
+	})
+
+	It("podman pull --repo-digest-only", func() {
+		session := podmanTest.Podman([]string{"pull", "-q", "--repo-digest-only", "quay.io/libpod/testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+		Expect(session.OutputToStringArray()).To(HaveLen(1))
+		Expect(session.OutputToString()).To(HavePrefix("quay.io/libpod/testdigest_v2s2@sha256:"))
+
+		session = podmanTest.Podman([]string{"rmi", "testdigest_v2s2:20200210"})
+		session.WaitWithDefaultTimeout()
+		Expect(session).Should(ExitCleanly())
+	})
+
 	It("podman pull without tag", func() {
 		session := podmanTest.Podman([]string{"pull", "-q", "quay.io/libpod/testdigest_v2s2"})
 		session.WaitWithDefaultTimeout()